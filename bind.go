@@ -0,0 +1,82 @@
+package jni
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Bind 按照结构体标签解析 className 的字段和方法，binding 必须是结构体指针：
+//
+//	type ArrayList struct {
+//		Class jni.Jclass       `jni:"class"`
+//		Add   jni.Method[bool]  `jni:"add,(Ljava/lang/Object;)Z"`
+//		Size  jni.Method[int32] `jni:"size,()I"`
+//	}
+//
+// 类型为 Field、StaticField、Method 或 StaticMethod 的字段使用标签 `jni:"name,descriptor"`，
+// 省略 name 时使用首字母小写的字段名；标签为 `jni:"class"` 的 Jclass 字段设置为类的全局引用。
+// 所有成员都会被解析，找不到的成员和类型不匹配的成员合并为一个错误返回。
+//
+// Bind 应该在加载时调用一次，之后各成员不会再次解析。已经解析的成员不能再绑定为其他成员，
+// 对同一个 binding 重复调用 Bind 时，名称和描述符不变的成员保持不变。
+func Bind(env Env, className string, binding any) error {
+	v := reflect.ValueOf(binding)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("jni: Bind 需要结构体指针，实际为 %T", binding)
+	}
+	v = v.Elem()
+
+	cls, err := env.findClass(className)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("jni")
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+
+		if ok && strings.TrimSpace(tag) == "class" {
+			if sf.Type.Kind() != reflect.Uintptr {
+				errs = append(errs, fmt.Errorf("jni: %s.%s 的类型应为 Jclass", t, sf.Name))
+				continue
+			}
+			v.Field(i).SetUint(uint64(cls))
+			continue
+		}
+
+		m, ok := v.Field(i).Addr().Interface().(binder)
+		if !ok {
+			continue
+		}
+		name, sig, _ := parseTag(tag)
+		if name == "" {
+			name = strings.ToLower(sf.Name[:1]) + sf.Name[1:]
+		}
+		if sig == "" {
+			errs = append(errs, fmt.Errorf("jni: %s.%s 缺少描述符，如 `jni:\"%s,()V\"`", t, sf.Name, name))
+			continue
+		}
+
+		if err := m.init(className, name, sig); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := m.Resolve(env); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Field、StaticField、Method 和 StaticMethod 都实现此接口
+type binder interface {
+	init(className, name, sig string) error
+	Resolve(env Env) error
+}
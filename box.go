@@ -0,0 +1,166 @@
+package jni
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Java 基本类型对应的包装类
+type boxType struct {
+	class   string // 包装类，如 "java/lang/Integer"
+	valueOf string // 静态方法 valueOf 的描述符
+	unbox   string // 拆箱方法名，如 "intValue"
+}
+
+// 以基本类型的描述符为键
+var boxTypes = map[byte]*boxType{
+	'Z': {"java/lang/Boolean", "(Z)Ljava/lang/Boolean;", "booleanValue"},
+	'B': {"java/lang/Byte", "(B)Ljava/lang/Byte;", "byteValue"},
+	'C': {"java/lang/Character", "(C)Ljava/lang/Character;", "charValue"},
+	'S': {"java/lang/Short", "(S)Ljava/lang/Short;", "shortValue"},
+	'I': {"java/lang/Integer", "(I)Ljava/lang/Integer;", "intValue"},
+	'J': {"java/lang/Long", "(J)Ljava/lang/Long;", "longValue"},
+	'F': {"java/lang/Float", "(F)Ljava/lang/Float;", "floatValue"},
+	'D': {"java/lang/Double", "(D)Ljava/lang/Double;", "doubleValue"},
+}
+
+// 拆箱得到的 Go 类型，与 Env 中 Get<Type>Field 等函数的返回值一致，只有 jint 使用 int32
+var unboxedTypes = map[byte]reflect.Type{
+	'Z': reflect.TypeOf(false),
+	'B': reflect.TypeOf(byte(0)),
+	'C': reflect.TypeOf(uint16(0)),
+	'S': reflect.TypeOf(int16(0)),
+	'I': reflect.TypeOf(int32(0)),
+	'J': reflect.TypeOf(int64(0)),
+	'F': reflect.TypeOf(float32(0)),
+	'D': reflect.TypeOf(float64(0)),
+}
+
+// 按固定顺序检查包装类，保证结果稳定
+const boxOrder = "ZBCSIJFD"
+
+// 包装类的字段描述符，如 "Ljava/lang/Integer;"
+func (b *boxType) descriptor() string {
+	return "L" + b.class + ";"
+}
+
+// 调用 valueOf 把 val 装箱
+func (env Env) box(primitive byte, val Jvalue) (Jobject, error) {
+	b := boxTypes[primitive]
+	cls, valueOf, err := env.staticMethodID(b.class, "valueOf", b.valueOf)
+	if err != nil {
+		return 0, err
+	}
+
+	obj := env.CallStaticObjectMethodA(cls, valueOf, val)
+	if obj == 0 {
+		return 0, env.exceptionOr("装箱 " + b.class + " 失败")
+	}
+	return obj, nil
+}
+
+// 调用包装类的 xxxValue() 方法拆箱，结果写入 v
+func (env Env) unbox(ref Jobject, p byte, v reflect.Value) error {
+	b := boxTypes[p]
+	_, id, err := env.methodID(b.class, b.unbox, "()"+string(p))
+	if err != nil {
+		return err
+	}
+
+	switch p {
+	case 'Z':
+		v.SetBool(env.CallBooleanMethodA(ref, id))
+	case 'B':
+		setInt(v, int64(int8(env.CallByteMethodA(ref, id))))
+	case 'C':
+		setInt(v, int64(env.CallCharMethodA(ref, id)))
+	case 'S':
+		setInt(v, int64(env.CallShortMethodA(ref, id)))
+	case 'I':
+		setInt(v, int64(env.CallIntMethodA(ref, id)))
+	case 'J':
+		setInt(v, env.CallLongMethodA(ref, id))
+	case 'F':
+		v.SetFloat(float64(env.CallFloatMethodA(ref, id)))
+	case 'D':
+		v.SetFloat(env.CallDoubleMethodA(ref, id))
+	}
+	return env.CheckException()
+}
+
+// Primitive 是可以装箱为 Java 包装类的 Go 基本类型，即除 uintptr（Jobject）以外的 Value
+type Primitive interface {
+	bool | int8 | byte | uint16 | int16 | int32 | int | int64 | float32 | float64
+}
+
+// Box 把 Go 的基本类型装箱为对应的 Java 包装类对象：
+//
+//	bool -> Boolean, byte/int8 -> Byte, uint16 -> Character, int16 -> Short,
+//	int32/int -> Integer, int64 -> Long, float32 -> Float, float64 -> Double
+//
+// v 不是上述类型时返回错误；valueOf 抛出的 Java 异常会被清除并以 *Exception 返回。返回的是局部引用。
+func (env Env) Box(v any) (Jobject, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return 0, fmt.Errorf("jni: 不能装箱 nil")
+	}
+	p, ok := primitiveKinds[rv.Kind()]
+	if !ok {
+		return 0, fmt.Errorf("jni: 不能装箱 %T", v)
+	}
+	return env.box(p, primitiveValue(rv, p))
+}
+
+// Unbox 是 Box 的逆操作：通过 IsInstanceOf 判断 obj 的包装类型，再调用 intValue() 等方法拆箱。
+// 返回值的类型为 bool、byte、uint16、int16、int32、int64、float32 或 float64。
+// obj 为 null 或不是包装类对象时返回错误。
+func (env Env) Unbox(obj Jobject) (any, error) {
+	if obj == 0 {
+		return nil, fmt.Errorf("jni: Unbox 的对象为 null")
+	}
+
+	for i := 0; i < len(boxOrder); i++ {
+		p := boxOrder[i]
+		cls, err := env.findClass(boxTypes[p].class)
+		if err != nil {
+			return nil, err
+		}
+		if !env.IsInstanceOf(obj, cls) {
+			continue
+		}
+
+		v := reflect.New(unboxedTypes[p]).Elem()
+		if err = env.unbox(obj, p, v); err != nil {
+			return nil, err
+		}
+		return v.Interface(), nil
+	}
+
+	className, err := env.classOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("jni: %s 不是基本类型的包装类", className)
+}
+
+// EncodeBoxed 是把 Go 基本类型装箱的 Encoder，可以用于集合和回调的参数转换
+func EncodeBoxed[T Primitive](env Env, v T) (Jobject, error) {
+	return env.Box(v)
+}
+
+// DecodeBoxed 是拆箱为 T 的 Decoder，包装类的值会按照 Go 的类型转换规则转换为 T。
+// obj 为 null 时返回错误。
+func DecodeBoxed[T Primitive](env Env, obj Jobject) (T, error) {
+	var ret T
+	v, err := env.Unbox(obj)
+	if err != nil {
+		return ret, err
+	}
+
+	rv := reflect.ValueOf(v)
+	target := reflect.TypeOf(ret)
+	if !rv.CanConvert(target) || (rv.Kind() == reflect.Bool) != (target.Kind() == reflect.Bool) {
+		return ret, fmt.Errorf("jni: %T 不能转换为 %T", v, ret)
+	}
+	return rv.Convert(target).Interface().(T), nil
+}
@@ -0,0 +1,230 @@
+package jni
+
+import (
+	"fmt"
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+)
+
+// DirectBuffer 是 AllocDirectBuffer 分配的 direct ByteBuffer，Go 和 Java 共享同一块 C 内存
+type DirectBuffer struct {
+	Bytes  []byte  // C 内存的 Go 视图，Free 后不能再使用
+	Buffer Jobject // java.nio.ByteBuffer 的局部引用，字节序为 ByteOrder.nativeOrder()
+
+	mem *directMemory
+}
+
+// Free 立即释放 C 内存，之后 Java 端不能再访问该 ByteBuffer。多次调用 Free 是安全的，
+// Java 对象被回收时也不会再次释放。
+//
+// 不调用 Free 时，内存在 ByteBuffer 被回收后通过 java.lang.ref.Cleaner 释放；
+// 没有 Cleaner 的 JVM（Java 8 及更早版本）上必须调用 Free。
+func (b *DirectBuffer) Free() {
+	b.mem.release()
+	b.Bytes = nil
+}
+
+type directMemory struct {
+	mu sync.Mutex
+	p  unsafe.Pointer
+}
+
+func (m *directMemory) release() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.p != nil {
+		CFree(m.p)
+		m.p = nil
+	}
+}
+
+// AllocDirectBuffer 使用 C 内存分配 n 字节的 direct ByteBuffer，内存初始化为 0
+func (env Env) AllocDirectBuffer(n int) (*DirectBuffer, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("jni: 缓冲区大小 %d 不能为负数", n)
+	}
+	_, order, err := env.methodID("java/nio/ByteBuffer", "order", "(Ljava/nio/ByteOrder;)Ljava/nio/ByteBuffer;")
+	if err != nil {
+		return nil, err
+	}
+	byteOrder, nativeOrder, err := env.staticMethodID("java/nio/ByteOrder", "nativeOrder", "()Ljava/nio/ByteOrder;")
+	if err != nil {
+		return nil, err
+	}
+
+	// malloc(0) 可能返回 NULL，而 NewDirectByteBuffer 要求地址有效
+	p := CMalloc(max(n, 1))
+	if p == nil {
+		return nil, env.exceptionOr("分配内存失败")
+	}
+	mem := &directMemory{p: p}
+	b := unsafe.Slice((*byte)(p), n)
+	clear(b)
+
+	buf := env.NewDirectByteBuffer(p, n)
+	if buf == 0 {
+		mem.release()
+		return nil, env.exceptionOr("NewDirectByteBuffer 失败")
+	}
+
+	// 没有 Cleaner 时不需要 handle，内存只能通过 Free 释放
+	h := cgo.NewHandle(mem)
+	registered, err := env.registerCleanup(buf, h)
+	if !registered {
+		h.Delete()
+	}
+	if err != nil {
+		env.DeleteLocalRef(buf)
+		mem.release()
+		return nil, err
+	}
+
+	no := env.CallStaticObjectMethodA(byteOrder, nativeOrder)
+	if no == 0 {
+		env.DeleteLocalRef(buf)
+		return nil, env.exceptionOr("ByteOrder.nativeOrder 失败")
+	}
+	defer env.DeleteLocalRef(no)
+	self, err := env.callObject(buf, order, ValueOf(no))
+	if err != nil {
+		env.DeleteLocalRef(buf)
+		return nil, err
+	}
+	env.DeleteLocalRef(self)
+
+	return &DirectBuffer{Bytes: b, Buffer: buf, mem: mem}, nil
+}
+
+// BufferOption 是 DirectBufferBytes 等函数的选项
+type BufferOption func(*bufferOptions)
+
+type bufferOptions struct {
+	remaining bool
+}
+
+// Remaining 只返回 position() 到 limit() 之间的元素，默认返回整个容量
+func Remaining() BufferOption {
+	return func(o *bufferOptions) {
+		o.remaining = true
+	}
+}
+
+// DirectBufferBytes 返回 direct java.nio.ByteBuffer 内存的 []byte 视图，不复制数据。
+// buf 不是 direct buffer（如 ByteBuffer.wrap 创建的 heap buffer）时返回错误。
+// 返回的切片只在 buf 存活期间有效。
+func (env Env) DirectBufferBytes(buf Jobject, opts ...BufferOption) ([]byte, error) {
+	return directBuffer[byte](env, buf, "java/nio/ByteBuffer", opts)
+}
+
+// DirectBufferInt32s 与 DirectBufferBytes 相同，用于 direct IntBuffer。
+// 字节序与本机不同的 buffer（如 ByteBuffer.order(BIG_ENDIAN).asIntBuffer()）无法直接访问，返回错误。
+func (env Env) DirectBufferInt32s(buf Jobject, opts ...BufferOption) ([]int32, error) {
+	return directBuffer[int32](env, buf, "java/nio/IntBuffer", opts)
+}
+
+// DirectBufferInt64s 与 DirectBufferInt32s 相同，用于 direct LongBuffer
+func (env Env) DirectBufferInt64s(buf Jobject, opts ...BufferOption) ([]int64, error) {
+	return directBuffer[int64](env, buf, "java/nio/LongBuffer", opts)
+}
+
+// DirectBufferFloat32s 与 DirectBufferInt32s 相同，用于 direct FloatBuffer
+func (env Env) DirectBufferFloat32s(buf Jobject, opts ...BufferOption) ([]float32, error) {
+	return directBuffer[float32](env, buf, "java/nio/FloatBuffer", opts)
+}
+
+// DirectBufferFloat64s 与 DirectBufferInt32s 相同，用于 direct DoubleBuffer
+func (env Env) DirectBufferFloat64s(buf Jobject, opts ...BufferOption) ([]float64, error) {
+	return directBuffer[float64](env, buf, "java/nio/DoubleBuffer", opts)
+}
+
+func directBuffer[T any](env Env, buf Jobject, className string, opts []BufferOption) ([]T, error) {
+	var o bufferOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cls, err := env.findClass(className)
+	if err != nil {
+		return nil, err
+	}
+	if buf == 0 || !env.IsInstanceOf(buf, cls) {
+		return nil, fmt.Errorf("jni: 对象不是 %s", className)
+	}
+
+	_, isDirect, err := env.methodID("java/nio/Buffer", "isDirect", "()Z")
+	if err != nil {
+		return nil, err
+	}
+	direct, err := env.callBoolean(buf, isDirect)
+	if err != nil {
+		return nil, err
+	}
+	p := env.GetDirectBufferAddress(buf)
+	if !direct || p == nil {
+		return nil, fmt.Errorf("jni: %s 不是 direct buffer", className)
+	}
+
+	var zero T
+	size := unsafe.Sizeof(zero)
+	if size > 1 {
+		if err = env.checkNativeOrder(buf, className); err != nil {
+			return nil, err
+		}
+		if uintptr(p)%unsafe.Alignof(zero) != 0 {
+			return nil, fmt.Errorf("jni: %s 的地址没有按 %d 字节对齐", className, unsafe.Alignof(zero))
+		}
+	}
+
+	// 对于 IntBuffer 等类型，容量、position 和 limit 都以元素为单位
+	s := unsafe.Slice((*T)(p), env.GetDirectBufferCapacity(buf))
+	if !o.remaining {
+		return s, nil
+	}
+
+	_, position, err := env.methodID("java/nio/Buffer", "position", "()I")
+	if err != nil {
+		return nil, err
+	}
+	_, limit, err := env.methodID("java/nio/Buffer", "limit", "()I")
+	if err != nil {
+		return nil, err
+	}
+	pos, err := env.callInt(buf, position)
+	if err != nil {
+		return nil, err
+	}
+	lim, err := env.callInt(buf, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s[pos:lim], nil
+}
+
+// 检查 buf 的字节序是否与本机相同
+func (env Env) checkNativeOrder(buf Jobject, className string) error {
+	_, order, err := env.methodID(className, "order", "()Ljava/nio/ByteOrder;")
+	if err != nil {
+		return err
+	}
+	byteOrder, nativeOrder, err := env.staticMethodID("java/nio/ByteOrder", "nativeOrder", "()Ljava/nio/ByteOrder;")
+	if err != nil {
+		return err
+	}
+
+	bo, err := env.callObject(buf, order)
+	if err != nil {
+		return err
+	}
+	defer env.DeleteLocalRef(bo)
+	no := env.CallStaticObjectMethodA(byteOrder, nativeOrder)
+	if no == 0 {
+		return env.exceptionOr("ByteOrder.nativeOrder 失败")
+	}
+	defer env.DeleteLocalRef(no)
+
+	if !env.IsSameObject(bo, no) {
+		return fmt.Errorf("jni: %s 的字节序与本机不同", className)
+	}
+	return nil
+}
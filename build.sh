@@ -1,3 +1,11 @@
 #!/usr/bin/env bash
+set -e
 
-go run ./cmd/gen >env.go
+# 用法: ./build.sh [jni.h]，不指定时使用 $JAVA_HOME/include/jni.h
+# 先写入临时文件，生成成功后再替换 env.go，失败时保留原来的 env.go
+cd "$(dirname "$0")"
+tmp=$(mktemp env.go.XXXXXX)
+trap 'rm -f "$tmp"' EXIT
+go run ./cmd/gen "$@" >"$tmp"
+chmod 644 "$tmp"
+mv "$tmp" env.go
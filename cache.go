@@ -0,0 +1,126 @@
+package jni
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 类的全局引用以及方法、字段 ID 的缓存。
+// 缓存的类永远不会被删除，因此其中的 ID 在 JVM 的整个生命周期内都有效。
+var (
+	classCache  sync.Map // string -> Jclass
+	memberCache sync.Map // memberKey -> uintptr
+)
+
+type memberKey struct {
+	class  string
+	name   string
+	sig    string
+	field  bool
+	static bool
+}
+
+// 查找类并缓存其全局引用，name 形如 "java/lang/String"
+func (env Env) findClass(name string) (Jclass, error) {
+	if cls, ok := classCache.Load(name); ok {
+		return cls.(Jclass), nil
+	}
+
+	local := env.FindClass(name)
+	if local == 0 {
+		if err := env.CheckException(); err != nil {
+			return 0, fmt.Errorf("jni: 找不到类 %s: %w", name, err)
+		}
+		return 0, fmt.Errorf("jni: 找不到类 %s", name)
+	}
+	defer env.DeleteLocalRef(local)
+
+	global := env.NewGlobalRef(local)
+	if cls, loaded := classCache.LoadOrStore(name, global); loaded {
+		env.DeleteGlobalRef(global)
+		return cls.(Jclass), nil
+	}
+	return global, nil
+}
+
+func (env Env) member(key memberKey) (Jclass, uintptr, error) {
+	cls, err := env.findClass(key.class)
+	if err != nil {
+		return 0, 0, err
+	}
+	if id, ok := memberCache.Load(key); ok {
+		return cls, id.(uintptr), nil
+	}
+
+	var id uintptr
+	switch {
+	case key.field && key.static:
+		id = env.GetStaticFieldID(cls, key.name, key.sig)
+	case key.field:
+		id = env.GetFieldID(cls, key.name, key.sig)
+	case key.static:
+		id = env.GetStaticMethodID(cls, key.name, key.sig)
+	default:
+		id = env.GetMethodID(cls, key.name, key.sig)
+	}
+	if id == 0 {
+		err = env.CheckException()
+		if err == nil {
+			err = fmt.Errorf("返回了空 ID")
+		}
+		return 0, 0, fmt.Errorf("jni: 找不到 %s.%s%s: %w", key.class, key.name, key.sig, err)
+	}
+
+	memberCache.Store(key, id)
+	return cls, id, nil
+}
+
+// 查找并缓存实例方法 ID
+func (env Env) methodID(class, name, sig string) (Jclass, JmethodID, error) {
+	return env.member(memberKey{class: class, name: name, sig: sig})
+}
+
+// 查找并缓存静态方法 ID
+func (env Env) staticMethodID(class, name, sig string) (Jclass, JmethodID, error) {
+	return env.member(memberKey{class: class, name: name, sig: sig, static: true})
+}
+
+// 查找并缓存实例字段 ID
+func (env Env) fieldID(class, name, sig string) (Jclass, JfieldID, error) {
+	return env.member(memberKey{class: class, name: name, sig: sig, field: true})
+}
+
+// 返回对象所属类的名称（形如 "com/demo/User"），并把该类放入缓存。
+// 这样之后按名称查找时不再经过 FindClass，不受当前线程 ClassLoader 的影响。
+func (env Env) classOf(obj Jobject) (string, error) {
+	cls := env.GetObjectClass(obj)
+	if cls == 0 {
+		return "", env.exceptionOr("GetObjectClass 失败")
+	}
+	defer env.DeleteLocalRef(cls)
+
+	_, getName, err := env.methodID("java/lang/Class", "getName", "()Ljava/lang/String;")
+	if err != nil {
+		return "", err
+	}
+	str := env.CallObjectMethodA(cls, getName)
+	if str == 0 {
+		return "", env.exceptionOr("Class.getName 失败")
+	}
+	defer env.DeleteLocalRef(str)
+	name := strings.ReplaceAll(env.goString(str), ".", "/")
+
+	if _, ok := classCache.Load(name); !ok {
+		global := env.NewGlobalRef(cls)
+		if _, loaded := classCache.LoadOrStore(name, global); loaded {
+			env.DeleteGlobalRef(global)
+		}
+	}
+	return name, nil
+}
+
+// 查找并缓存静态字段 ID
+func (env Env) staticFieldID(class, name, sig string) (Jclass, JfieldID, error) {
+	return env.member(memberKey{class: class, name: name, sig: sig, field: true, static: true})
+}
@@ -0,0 +1,40 @@
+package jni
+
+// 以下函数在调用 Java 方法后检查异常，抛出异常时以 error 返回
+
+func (env Env) callObject(obj Jobject, method JmethodID, args ...Jvalue) (Jobject, error) {
+	ret := env.CallObjectMethodA(obj, method, args...)
+	if err := env.CheckException(); err != nil {
+		return 0, err
+	}
+	return ret, nil
+}
+
+func (env Env) callBoolean(obj Jobject, method JmethodID, args ...Jvalue) (bool, error) {
+	ret := env.CallBooleanMethodA(obj, method, args...)
+	if err := env.CheckException(); err != nil {
+		return false, err
+	}
+	return ret, nil
+}
+
+func (env Env) callInt(obj Jobject, method JmethodID, args ...Jvalue) (int, error) {
+	ret := env.CallIntMethodA(obj, method, args...)
+	if err := env.CheckException(); err != nil {
+		return 0, err
+	}
+	return ret, nil
+}
+
+func (env Env) callVoid(obj Jobject, method JmethodID, args ...Jvalue) error {
+	env.CallVoidMethodA(obj, method, args...)
+	return env.CheckException()
+}
+
+func (env Env) newObject(cls Jclass, ctor JmethodID, args ...Jvalue) (Jobject, error) {
+	obj := env.NewObjectA(cls, ctor, args...)
+	if obj == 0 {
+		return 0, env.exceptionOr("创建对象失败")
+	}
+	return obj, nil
+}
@@ -0,0 +1,167 @@
+package jni
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unsafe"
+)
+
+// 生成 Java 端 peer 类的字节码。peer 类的结构固定为：
+//
+//	public final class <name> extends <super> implements <interfaces> {
+//	    private final long handle;
+//	    public <name>(long handle) { super(); this.handle = handle; }
+//	    public native ...;
+//	}
+//
+// 使用 49 (Java 5) 版本的类文件格式，不需要 StackMapTable。
+// 构造函数之外的方法都是 native 方法，由 RegisterNatives 绑定到 Go 函数。
+
+const (
+	accPublic  = 0x0001
+	accPrivate = 0x0002
+	accStatic  = 0x0008
+	accFinal   = 0x0010
+	accSuper   = 0x0020
+	accNative  = 0x0100
+)
+
+const (
+	cpUtf8        = 1
+	cpClass       = 7
+	cpFieldref    = 9
+	cpMethodref   = 10
+	cpNameAndType = 12
+)
+
+type classWriter struct {
+	pool  bytes.Buffer
+	count uint16
+	index map[string]uint16
+}
+
+func (w *classWriter) add(key string, tag byte, data ...uint16) uint16 {
+	if i, ok := w.index[key]; ok {
+		return i
+	}
+	w.pool.WriteByte(tag)
+	for _, d := range data {
+		binary.Write(&w.pool, binary.BigEndian, d)
+	}
+	w.count++
+	w.index[key] = w.count
+	return w.count
+}
+
+func (w *classWriter) utf8(s string) uint16 {
+	if i, ok := w.index["U"+s]; ok {
+		return i
+	}
+	// peer 类中只有 ASCII 名称，不需要转换为 modified UTF-8
+	w.pool.WriteByte(cpUtf8)
+	binary.Write(&w.pool, binary.BigEndian, uint16(len(s)))
+	w.pool.WriteString(s)
+	w.count++
+	w.index["U"+s] = w.count
+	return w.count
+}
+
+func (w *classWriter) class(name string) uint16 {
+	return w.add("C"+name, cpClass, w.utf8(name))
+}
+
+func (w *classWriter) nameAndType(name, desc string) uint16 {
+	return w.add("N"+name+":"+desc, cpNameAndType, w.utf8(name), w.utf8(desc))
+}
+
+func (w *classWriter) fieldref(class, name, desc string) uint16 {
+	return w.add("F"+class+"."+name+":"+desc, cpFieldref, w.class(class), w.nameAndType(name, desc))
+}
+
+func (w *classWriter) methodref(class, name, desc string) uint16 {
+	return w.add("M"+class+"."+name+":"+desc, cpMethodref, w.class(class), w.nameAndType(name, desc))
+}
+
+// peer 类中的 native 方法，fn 是实现该方法的 cgo 导出函数
+type peerMethod struct {
+	name   string
+	sig    string
+	static bool
+	fn     unsafe.Pointer
+}
+
+// 生成 peer 类的字节码，name、super 和 interfaces 使用 "java/lang/Object" 形式的内部名称
+func peerClassBytes(name, super string, interfaces []string, methods []peerMethod) []byte {
+	w := &classWriter{index: make(map[string]uint16)}
+
+	thisClass := w.class(name)
+	superClass := w.class(super)
+	ifaces := make([]uint16, len(interfaces))
+	for i, iface := range interfaces {
+		ifaces[i] = w.class(iface)
+	}
+
+	handleName := w.utf8("handle")
+	handleDesc := w.utf8("J")
+	handleRef := w.fieldref(name, "handle", "J")
+	superInit := w.methodref(super, "<init>", "()V")
+	initName := w.utf8("<init>")
+	initDesc := w.utf8("(J)V")
+	codeName := w.utf8("Code")
+
+	type method struct {
+		access     uint16
+		name, desc uint16
+	}
+	natives := make([]method, len(methods))
+	for i, m := range methods {
+		access := uint16(accPublic | accNative)
+		if m.static {
+			access |= accStatic
+		}
+		natives[i] = method{access, w.utf8(m.name), w.utf8(m.sig)}
+	}
+
+	// 构造函数：aload_0; invokespecial super.<init>()V; aload_0; lload_1; putfield handle; return
+	code := []byte{
+		0x2a,
+		0xb7, byte(superInit >> 8), byte(superInit),
+		0x2a,
+		0x1f,
+		0xb5, byte(handleRef >> 8), byte(handleRef),
+		0xb1,
+	}
+
+	var out bytes.Buffer
+	put := func(v ...any) {
+		for _, x := range v {
+			binary.Write(&out, binary.BigEndian, x)
+		}
+	}
+
+	put(uint32(0xCAFEBABE), uint16(0), uint16(49))
+	put(w.count + 1)
+	out.Write(w.pool.Bytes())
+	put(uint16(accPublic|accFinal|accSuper), thisClass, superClass)
+	put(uint16(len(ifaces)))
+	for _, i := range ifaces {
+		put(i)
+	}
+
+	// 字段
+	put(uint16(1), uint16(accPrivate|accFinal), handleName, handleDesc, uint16(0))
+
+	// 方法
+	put(uint16(1 + len(natives)))
+	put(uint16(accPublic), initName, initDesc, uint16(1))
+	put(codeName, uint32(12+len(code)), uint16(3), uint16(3), uint32(len(code)))
+	out.Write(code)
+	put(uint16(0), uint16(0)) // exception_table_length, attributes_count
+	for _, m := range natives {
+		put(m.access, m.name, m.desc, uint16(0))
+	}
+
+	// 类属性
+	put(uint16(0))
+	return out.Bytes()
+}
@@ -0,0 +1,310 @@
+// Package classfile 使用纯 Go 解析 Java 类文件（.class），只读取生成绑定代码所需的信息：
+// 类名、父类、接口、字段、方法、访问标志以及字段的常量值。
+package classfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+// 访问标志
+const (
+	AccPublic       = 0x0001
+	AccPrivate      = 0x0002
+	AccProtected    = 0x0004
+	AccStatic       = 0x0008
+	AccFinal        = 0x0010
+	AccSuper        = 0x0020 // 类
+	AccSynchronized = 0x0020 // 方法
+	AccVolatile     = 0x0040 // 字段
+	AccBridge       = 0x0040 // 方法
+	AccTransient    = 0x0080 // 字段
+	AccVarargs      = 0x0080 // 方法
+	AccNative       = 0x0100
+	AccInterface    = 0x0200
+	AccAbstract     = 0x0400
+	AccStrict       = 0x0800
+	AccSynthetic    = 0x1000
+	AccAnnotation   = 0x2000
+	AccEnum         = 0x4000
+	AccModule       = 0x8000
+)
+
+// Class 是解析后的类文件，所有类名都使用 "java/lang/Object" 形式的内部名称
+type Class struct {
+	MinorVersion uint16
+	MajorVersion uint16
+	AccessFlags  uint16
+	Name         string
+	SuperName    string // java/lang/Object 和 module-info 为空
+	Interfaces   []string
+	Fields       []*Field
+	Methods      []*Method
+}
+
+// Field 是类中声明的字段
+type Field struct {
+	AccessFlags uint16
+	Name        string
+	Descriptor  string
+
+	// ConstantValue 属性的值，类型为 int32、int64、float32、float64 或 string；没有该属性时为 nil
+	ConstantValue any
+}
+
+// Method 是类中声明的方法，包括构造函数 <init> 和静态初始化 <clinit>
+type Method struct {
+	AccessFlags uint16
+	Name        string
+	Descriptor  string
+}
+
+// Is 判断是否设置了访问标志 flag
+func (c *Class) Is(flag uint16) bool { return c.AccessFlags&flag != 0 }
+
+// Is 判断是否设置了访问标志 flag
+func (f *Field) Is(flag uint16) bool { return f.AccessFlags&flag != 0 }
+
+// Is 判断是否设置了访问标志 flag
+func (m *Method) Is(flag uint16) bool { return m.AccessFlags&flag != 0 }
+
+// ErrNotClassFile 表示数据不是以 0xCAFEBABE 开头
+var ErrNotClassFile = errors.New("classfile: 不是 Java 类文件")
+
+// 常量池类型
+const (
+	constUtf8               = 1
+	constInteger            = 3
+	constFloat              = 4
+	constLong               = 5
+	constDouble             = 6
+	constClass              = 7
+	constString             = 8
+	constFieldref           = 9
+	constMethodref          = 10
+	constInterfaceMethodref = 11
+	constNameAndType        = 12
+	constMethodHandle       = 15
+	constMethodType         = 16
+	constDynamic            = 17
+	constInvokeDynamic      = 18
+	constModule             = 19
+	constPackage            = 20
+)
+
+type constant struct {
+	tag   byte
+	index uint16 // Class、String 指向的 Utf8
+	value any    // Utf8 为 string，数值常量为对应的 Go 类型
+}
+
+type reader struct {
+	r    *bufio.Reader
+	pool []constant
+	err  error
+}
+
+func (r *reader) read(data any) {
+	if r.err == nil {
+		if err := binary.Read(r.r, binary.BigEndian, data); err != nil {
+			r.err = err
+		}
+	}
+}
+
+func (r *reader) u1() (v uint8)  { r.read(&v); return }
+func (r *reader) u2() (v uint16) { r.read(&v); return }
+func (r *reader) u4() (v uint32) { r.read(&v); return }
+
+// 长度来自文件本身，不能直接按长度分配内存：按实际读到的数据增长，
+// 避免损坏的类文件导致巨大的分配
+func (r *reader) bytes(n int64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	b, err := io.ReadAll(io.LimitReader(r.r, n))
+	if err == nil && int64(len(b)) < n {
+		err = io.ErrUnexpectedEOF
+	}
+	r.err = err
+	return b
+}
+
+func (r *reader) fail(format string, args ...any) {
+	if r.err == nil {
+		r.err = fmt.Errorf("classfile: "+format, args...)
+	}
+}
+
+// 读取 Utf8 常量
+func (r *reader) utf8(i uint16) string {
+	if int(i) >= len(r.pool) || r.pool[i].tag != constUtf8 {
+		r.fail("常量池第 %d 项不是 Utf8", i)
+		return ""
+	}
+	return r.pool[i].value.(string)
+}
+
+// 读取 Class 常量的名称，i 为 0 时返回空字符串
+func (r *reader) class(i uint16) string {
+	if i == 0 {
+		return ""
+	}
+	if int(i) >= len(r.pool) || r.pool[i].tag != constClass {
+		r.fail("常量池第 %d 项不是 Class", i)
+		return ""
+	}
+	return r.utf8(r.pool[i].index)
+}
+
+// Parse 从 r 中读取并解析一个类文件
+func Parse(r io.Reader) (*Class, error) {
+	cr := &reader{r: bufio.NewReader(r)}
+	if magic := cr.u4(); cr.err == nil && magic != 0xCAFEBABE {
+		return nil, ErrNotClassFile
+	}
+
+	c := new(Class)
+	c.MinorVersion = cr.u2()
+	c.MajorVersion = cr.u2()
+	cr.readPool()
+	c.AccessFlags = cr.u2()
+	c.Name = cr.class(cr.u2())
+	c.SuperName = cr.class(cr.u2())
+
+	c.Interfaces = make([]string, cr.u2())
+	for i := range c.Interfaces {
+		c.Interfaces[i] = cr.class(cr.u2())
+	}
+
+	n := cr.u2()
+	for i := 0; i < int(n) && cr.err == nil; i++ {
+		f := &Field{AccessFlags: cr.u2(), Name: cr.utf8(cr.u2()), Descriptor: cr.utf8(cr.u2())}
+		cr.attributes(func(name string, data []byte) {
+			if name == "ConstantValue" && len(data) == 2 {
+				f.ConstantValue = cr.constantValue(binary.BigEndian.Uint16(data))
+			}
+		})
+		c.Fields = append(c.Fields, f)
+	}
+
+	n = cr.u2()
+	for i := 0; i < int(n) && cr.err == nil; i++ {
+		m := &Method{AccessFlags: cr.u2(), Name: cr.utf8(cr.u2()), Descriptor: cr.utf8(cr.u2())}
+		cr.attributes(nil)
+		c.Methods = append(c.Methods, m)
+	}
+	cr.attributes(nil)
+
+	if cr.err != nil {
+		if cr.err == io.EOF || cr.err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("classfile: 文件不完整: %w", io.ErrUnexpectedEOF)
+		}
+		return nil, cr.err
+	}
+	return c, nil
+}
+
+// ParseBytes 解析内存中的类文件
+func ParseBytes(b []byte) (*Class, error) {
+	return Parse(bytes.NewReader(b))
+}
+
+func (r *reader) readPool() {
+	count := r.u2()
+	if r.err == nil && count == 0 {
+		r.fail("常量池大小为 0")
+	}
+	// 常量池下标从 1 开始，第 0 项不使用
+	r.pool = make([]constant, count)
+	for i := 1; i < int(count) && r.err == nil; i++ {
+		c := &r.pool[i]
+		c.tag = r.u1()
+		switch c.tag {
+		case constUtf8:
+			c.value = decodeModifiedUTF8(r.bytes(int64(r.u2())))
+		case constInteger:
+			c.value = int32(r.u4())
+		case constFloat:
+			c.value = math.Float32frombits(r.u4())
+		case constLong:
+			c.value = int64(uint64(r.u4())<<32 | uint64(r.u4()))
+			i++ // long 和 double 占两项
+		case constDouble:
+			c.value = math.Float64frombits(uint64(r.u4())<<32 | uint64(r.u4()))
+			i++
+		case constClass, constString, constMethodType, constModule, constPackage:
+			c.index = r.u2()
+		case constFieldref, constMethodref, constInterfaceMethodref, constNameAndType,
+			constDynamic, constInvokeDynamic:
+			r.u4()
+		case constMethodHandle:
+			r.u1()
+			r.u2()
+		default:
+			r.fail("未知的常量池类型 %d（第 %d 项）", c.tag, i)
+		}
+	}
+}
+
+// 读取属性表，fn 为 nil 时跳过所有属性
+func (r *reader) attributes(fn func(name string, data []byte)) {
+	n := r.u2()
+	for i := 0; i < int(n) && r.err == nil; i++ {
+		name := r.utf8(r.u2())
+		data := r.bytes(int64(r.u4()))
+		if fn != nil && r.err == nil {
+			fn(name, data)
+		}
+	}
+}
+
+func (r *reader) constantValue(i uint16) any {
+	if int(i) >= len(r.pool) {
+		r.fail("ConstantValue 下标 %d 越界", i)
+		return nil
+	}
+	c := r.pool[i]
+	switch c.tag {
+	case constInteger, constFloat, constLong, constDouble:
+		return c.value
+	case constString:
+		return r.utf8(c.index)
+	}
+	r.fail("常量池第 %d 项不能作为 ConstantValue", i)
+	return nil
+}
+
+// 类文件使用 modified UTF-8：'\0' 编码为两个字节，补充平面的字符编码为两个代理项各三个字节
+func decodeModifiedUTF8(b []byte) string {
+	codes := make([]uint16, 0, len(b))
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c < 0x80:
+			codes = append(codes, uint16(c))
+			i++
+		case c&0xE0 == 0xC0 && i+1 < len(b):
+			codes = append(codes, uint16(c&0x1F)<<6|uint16(b[i+1]&0x3F))
+			i += 2
+		case c&0xF0 == 0xE0 && i+2 < len(b):
+			codes = append(codes, uint16(c&0x0F)<<12|uint16(b[i+1]&0x3F)<<6|uint16(b[i+2]&0x3F))
+			i += 3
+		default:
+			codes = append(codes, 0xFFFD)
+			i++
+		}
+	}
+
+	var sb strings.Builder
+	for _, r := range utf16.Decode(codes) {
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
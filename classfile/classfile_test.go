@@ -0,0 +1,131 @@
+package classfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readDemo(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "Demo.class"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParse(t *testing.T) {
+	c, err := ParseBytes(readDemo(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Class{
+		MajorVersion: 52,
+		AccessFlags:  AccPublic | AccSuper | AccAbstract,
+		Name:         "com/demo/Demo",
+		SuperName:    "java/lang/Object",
+		Interfaces:   []string{"java/lang/Runnable"},
+		Fields: []*Field{
+			{AccPublic | AccStatic | AccFinal, "MAX", "I", int32(42)},
+			{AccPublic | AccStatic | AccFinal, "BIG", "J", int64(1) << 40},
+			{AccPublic | AccStatic | AccFinal, "HALF", "F", float32(0.5)},
+			{AccPublic | AccStatic | AccFinal, "PI", "D", 3.14},
+			{AccPublic | AccStatic | AccFinal, "NAME", "Ljava/lang/String;", "你好\x00😀"},
+			{AccPublic | AccStatic, "counter", "I", nil},
+			{AccProtected, "label", "Ljava/lang/String;", nil},
+		},
+		Methods: []*Method{
+			{AccPublic, "<init>", "()V"},
+			{AccPublic | AccNative, "hash", "([BI)J"},
+			{AccPublic | AccStatic | AccNative, "hash", "(Ljava/lang/String;)J"},
+			{AccPublic | AccAbstract, "names", "(Ljava/util/List;)[Ljava/lang/String;"},
+			{AccPublic | AccStatic | AccNative, "reset", "()V"},
+		},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("解析结果为\n%s\n期望\n%s", dump(c), dump(want))
+	}
+}
+
+func dump(c *Class) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%#x %s extends %s implements %v (%d.%d)", c.AccessFlags, c.Name, c.SuperName, c.Interfaces, c.MajorVersion, c.MinorVersion)
+	for _, f := range c.Fields {
+		fmt.Fprintf(&sb, "\n  field %#x %s %s = %#v", f.AccessFlags, f.Name, f.Descriptor, f.ConstantValue)
+	}
+	for _, m := range c.Methods {
+		fmt.Fprintf(&sb, "\n  method %#x %s%s", m.AccessFlags, m.Name, m.Descriptor)
+	}
+	return sb.String()
+}
+
+func TestParseError(t *testing.T) {
+	data := readDemo(t)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"空文件", nil, io.ErrUnexpectedEOF},
+		{"不是类文件", []byte("PK\x03\x04 not a class"), ErrNotClassFile},
+		{"只有文件头", data[:8], io.ErrUnexpectedEOF},
+		{"常量池被截断", data[:40], io.ErrUnexpectedEOF},
+		{"方法被截断", data[:len(data)-10], io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		_, err := ParseBytes(tt.data)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: 错误为 %v，期望 %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParseBadPool(t *testing.T) {
+	header := []byte{0xCA, 0xFE, 0xBA, 0xBE, 0, 0, 0, 52}
+	tests := []struct {
+		name string
+		pool []byte
+		want string
+	}{
+		{"常量池大小为 0", []byte{0, 0}, "常量池大小为 0"},
+		{"未知的常量类型", []byte{0, 2, 99}, "未知的常量池类型 99（第 1 项）"},
+		// 声明的长度远大于文件，不能按声明的长度分配内存
+		{"Utf8 长度越界", []byte{0, 2, 1, 0xFF, 0xFF, 'a'}, "文件不完整"},
+		// this_class 指向 Utf8 而不是 Class
+		{"类名不是 Class 常量", []byte{0, 2, 1, 0, 1, 'A', 0, 0x21, 0, 1}, "常量池第 1 项不是 Class"},
+	}
+	for _, tt := range tests {
+		_, err := ParseBytes(append(header[:len(header):len(header)], tt.pool...))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: 错误为 %v，期望包含 %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeModifiedUTF8(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want string
+	}{
+		{[]byte("abc"), "abc"},
+		{[]byte{0xC0, 0x80}, "\x00"},
+		{[]byte{0xE4, 0xBD, 0xA0}, "你"},
+		// U+1F600 编码为两个代理项
+		{[]byte{0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80}, "😀"},
+		// 不完整的多字节序列
+		{[]byte{'a', 0xE4, 0xBD}, "a��"},
+	}
+	for _, tt := range tests {
+		if got := decodeModifiedUTF8(tt.in); got != tt.want {
+			t.Errorf("decodeModifiedUTF8(% x) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
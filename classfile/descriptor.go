@@ -0,0 +1,87 @@
+package classfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseMethodDescriptor 把方法描述符（如 "(I[Ljava/lang/String;)V"）拆分为参数和返回值的字段描述符
+func ParseMethodDescriptor(descriptor string) (params []string, ret string, err error) {
+	if !strings.HasPrefix(descriptor, "(") {
+		return nil, "", fmt.Errorf("classfile: 无效的方法描述符 %q", descriptor)
+	}
+
+	rest := descriptor[1:]
+	for !strings.HasPrefix(rest, ")") {
+		var param string
+		if param, rest, err = NextFieldDescriptor(rest); err != nil {
+			return nil, "", fmt.Errorf("classfile: 无效的方法描述符 %q: %w", descriptor, err)
+		}
+		params = append(params, param)
+	}
+
+	ret = rest[1:]
+	if ret != "V" {
+		var tail string
+		if ret, tail, err = NextFieldDescriptor(ret); err != nil || tail != "" {
+			return nil, "", fmt.Errorf("classfile: 无效的方法描述符 %q", descriptor)
+		}
+	}
+	return params, ret, nil
+}
+
+// NextFieldDescriptor 读取 s 开头的一个字段描述符
+func NextFieldDescriptor(s string) (desc, rest string, err error) {
+	i := 0
+	for i < len(s) && s[i] == '[' {
+		i++
+	}
+	if i == len(s) {
+		return "", "", fmt.Errorf("描述符不完整")
+	}
+
+	switch s[i] {
+	case 'Z', 'B', 'C', 'S', 'I', 'J', 'F', 'D':
+		return s[:i+1], s[i+1:], nil
+	case 'L':
+		end := strings.IndexByte(s[i:], ';')
+		if end <= 1 {
+			return "", "", fmt.Errorf("缺少类名或 ';'")
+		}
+		return s[:i+end+1], s[i+end+1:], nil
+	}
+	return "", "", fmt.Errorf("无效的类型 %q", s[i])
+}
+
+// JavaName 把字段描述符转换为 Java 源代码中的类型名，如 "[Ljava/lang/String;" -> "java.lang.String[]"
+func JavaName(desc string) string {
+	dims := 0
+	for dims < len(desc) && desc[dims] == '[' {
+		dims++
+	}
+
+	var name string
+	switch elem := desc[dims:]; elem {
+	case "Z":
+		name = "boolean"
+	case "B":
+		name = "byte"
+	case "C":
+		name = "char"
+	case "S":
+		name = "short"
+	case "I":
+		name = "int"
+	case "J":
+		name = "long"
+	case "F":
+		name = "float"
+	case "D":
+		name = "double"
+	case "V":
+		name = "void"
+	default:
+		name = strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(elem, "L"), ";"), "/", ".")
+	}
+	return name + strings.Repeat("[]", dims)
+}
@@ -0,0 +1,96 @@
+package classfile
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMethodDescriptor(t *testing.T) {
+	tests := []struct {
+		desc   string
+		params []string
+		ret    string
+	}{
+		{"()V", nil, "V"},
+		{"(I)J", []string{"I"}, "J"},
+		{"([BI)J", []string{"[B", "I"}, "J"},
+		{"(ZBCSIJFD)D", []string{"Z", "B", "C", "S", "I", "J", "F", "D"}, "D"},
+		{"(Ljava/lang/String;[[I)[Ljava/lang/Object;", []string{"Ljava/lang/String;", "[[I"}, "[Ljava/lang/Object;"},
+		{"(Lcom/demo/Outer$Inner;)Lcom/demo/Outer$Inner;", []string{"Lcom/demo/Outer$Inner;"}, "Lcom/demo/Outer$Inner;"},
+	}
+	for _, tt := range tests {
+		params, ret, err := ParseMethodDescriptor(tt.desc)
+		if err != nil {
+			t.Errorf("ParseMethodDescriptor(%q): %v", tt.desc, err)
+			continue
+		}
+		if !slices.Equal(params, tt.params) || ret != tt.ret {
+			t.Errorf("ParseMethodDescriptor(%q) = %q, %q, want %q, %q", tt.desc, params, ret, tt.params, tt.ret)
+		}
+	}
+}
+
+func TestParseMethodDescriptorError(t *testing.T) {
+	for _, desc := range []string{
+		"",
+		"V",
+		"I)V",
+		"(I",
+		"(I)",
+		"(X)V",
+		"(Ljava/lang/String)V",
+		"(L;)V",
+		"([)V",
+		"()VV",
+		"()[V",
+		"()II",
+		"(V)V",
+	} {
+		if params, ret, err := ParseMethodDescriptor(desc); err == nil {
+			t.Errorf("ParseMethodDescriptor(%q) = %q, %q，没有返回错误", desc, params, ret)
+		}
+	}
+}
+
+func TestNextFieldDescriptor(t *testing.T) {
+	tests := []struct {
+		s, desc, rest string
+		ok            bool
+	}{
+		{"I", "I", "", true},
+		{"IJ", "I", "J", true},
+		{"[[BZ", "[[B", "Z", true},
+		{"Ljava/lang/String;I", "Ljava/lang/String;", "I", true},
+		{"[Ljava/util/List;)V", "[Ljava/util/List;", ")V", true},
+		{"", "", "", false},
+		{"[[", "", "", false},
+		{"Ljava/lang/String", "", "", false},
+		{"L;I", "", "", false},
+		{"V", "", "", false},
+		{")V", "", "", false},
+	}
+	for _, tt := range tests {
+		desc, rest, err := NextFieldDescriptor(tt.s)
+		if (err == nil) != tt.ok || desc != tt.desc || rest != tt.rest {
+			t.Errorf("NextFieldDescriptor(%q) = %q, %q, %v", tt.s, desc, rest, err)
+		}
+	}
+}
+
+func TestJavaName(t *testing.T) {
+	tests := []struct {
+		desc, name string
+	}{
+		{"V", "void"},
+		{"Z", "boolean"},
+		{"J", "long"},
+		{"[B", "byte[]"},
+		{"Ljava/lang/String;", "java.lang.String"},
+		{"[[Ljava/lang/Object;", "java.lang.Object[][]"},
+	}
+	for _, tt := range tests {
+		if got := JavaName(tt.desc); got != tt.name {
+			t.Errorf("JavaName(%q) = %q, want %q", tt.desc, got, tt.name)
+		}
+	}
+}
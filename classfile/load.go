@@ -0,0 +1,105 @@
+package classfile
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Load 读取 paths 中的所有类：参数可以是 .class 文件、.jar 文件或包含它们的目录（递归查找）。
+// 结果按类名排序，jar 中的 module-info.class 和 META-INF 下的类（多版本 jar）会被忽略。
+func Load(paths ...string) ([]*Class, error) {
+	var classes []*Class
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var list []*Class
+		if info.IsDir() {
+			list, err = loadDir(path)
+		} else {
+			list, err = loadFile(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, list...)
+	}
+
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i].Name < classes[j].Name
+	})
+	return classes, nil
+}
+
+func loadDir(dir string) ([]*Class, error) {
+	var classes []*Class
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if !strings.HasSuffix(path, ".class") && !strings.HasSuffix(path, ".jar") {
+			return nil
+		}
+		list, err := loadFile(path)
+		classes = append(classes, list...)
+		return err
+	})
+	return classes, err
+}
+
+func loadFile(path string) ([]*Class, error) {
+	if strings.HasSuffix(path, ".jar") {
+		return LoadJar(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if c.Is(AccModule) {
+		return nil, nil
+	}
+	return []*Class{c}, nil
+}
+
+// LoadJar 读取 jar 文件中的所有类
+func LoadJar(path string) ([]*Class, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var classes []*Class
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, ".class") || strings.HasPrefix(f.Name, "META-INF/") ||
+			strings.HasSuffix(f.Name, "module-info.class") {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%s!%s: %w", path, f.Name, err)
+		}
+		c, err := Parse(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s!%s: %w", path, f.Name, err)
+		}
+		classes = append(classes, c)
+	}
+	return classes, nil
+}
@@ -0,0 +1,67 @@
+package classfile
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	data := readDemo(t)
+
+	// jar 中 META-INF 下的多版本类和 module-info.class 会被忽略，损坏的类文件报告 jar 中的路径
+	dir := t.TempDir()
+	jar := filepath.Join(dir, "demo.jar")
+	writeJar(t, jar, map[string][]byte{
+		"com/demo/Demo.class":                     data,
+		"META-INF/versions/9/com/demo/Demo.class": []byte("broken"),
+		"module-info.class":                       []byte("broken"),
+		"com/demo/readme.txt":                     []byte("not a class"),
+	})
+
+	classes, err := Load(jar, filepath.Join("testdata", "Demo.class"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(classes) != 2 || classes[0].Name != "com/demo/Demo" || classes[1].Name != "com/demo/Demo" {
+		t.Errorf("读取到 %d 个类", len(classes))
+	}
+
+	// 目录会递归查找 .class 和 .jar
+	classes, err = Load("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(classes) != 1 {
+		t.Errorf("testdata 中读取到 %d 个类，期望 1 个", len(classes))
+	}
+
+	broken := filepath.Join(dir, "broken.jar")
+	writeJar(t, broken, map[string][]byte{"com/demo/Broken.class": data[:20]})
+	if _, err = Load(broken); err == nil || !strings.Contains(err.Error(), "broken.jar!com/demo/Broken.class") {
+		t.Errorf("错误为 %v，期望包含 jar 中的路径", err)
+	}
+}
+
+func writeJar(t *testing.T, path string, files map[string][]byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
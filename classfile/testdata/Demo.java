@@ -0,0 +1,24 @@
+// Demo.class 对应的源代码，用于 classfile、jnibind 和 jnistub 的测试。
+// NAME 中的 '\0' 和补充平面字符在类文件中使用 modified UTF-8 编码。
+package com.demo;
+
+public abstract class Demo implements Runnable {
+    public static final int MAX = 42;
+    public static final long BIG = 1L << 40;
+    public static final float HALF = 0.5f;
+    public static final double PI = 3.14;
+    public static final String NAME = "你好\0😀";
+    public static int counter;
+    protected String label;
+
+    public Demo() {
+    }
+
+    public native long hash(byte[] data, int seed);
+
+    public static native long hash(String s);
+
+    public abstract String[] names(java.util.List<String> list);
+
+    public static native void reset();
+}
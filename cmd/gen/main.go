@@ -3,14 +3,105 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/ClarkGuan/jni/tool"
 )
 
 func main() {
-	var pkg string
+	var pkg, list string
 	flag.StringVar(&pkg, "p", "jni", "指定 Go package 名称")
+	flag.StringVar(&list, "list", "", "上次生成的函数列表，用于比较 jni.h 的变化，默认为模块根目录下的 tool/functions.txt")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "用法: gen [-p package] [-list file] [jni.h]\n\n不指定 jni.h 时使用 $JAVA_HOME/include/jni.h\n\n")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	fmt.Print(tool.GenerateCode(pkg))
+	if err := run(pkg, list); err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(pkg, list string) error {
+	if list == "" {
+		root, err := moduleRoot()
+		if err != nil {
+			return err
+		}
+		list = filepath.Join(root, "tool", "functions.txt")
+	}
+
+	var path string
+	switch flag.NArg() {
+	case 0:
+		var err error
+		if path, err = tool.FindHeader(); err != nil {
+			return err
+		}
+	case 1:
+		path = flag.Arg(0)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	h, err := tool.ReadHeader(path)
+	if err != nil {
+		return err
+	}
+
+	names := h.Functions()
+	last, err := tool.ReadFunctions(list)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		added, removed := tool.Diff(last, names)
+		report("新增", added)
+		report("删除", removed)
+	}
+
+	code, err := tool.GenerateCode(pkg, h)
+	if err != nil {
+		return err
+	}
+	report("跳过不定参数的", h.VarArgs())
+
+	fmt.Print(code)
+	return tool.WriteFunctions(list, names)
+}
+
+const modulePath = "github.com/ClarkGuan/jni"
+
+// 从当前目录向上查找本模块的根目录，找不到时需要通过 -list 指定函数列表
+func moduleRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" && fields[1] == modulePath {
+					return dir, nil
+				}
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("当前目录不在 %s 模块中，需要通过 -list 指定函数列表", modulePath)
+		}
+		dir = parent
+	}
+}
+
+func report(what string, names []string) {
+	if len(names) > 0 {
+		fmt.Fprintf(os.Stderr, "%s %d 个函数: %s\n", what, len(names), strings.Join(names, ", "))
+	}
 }
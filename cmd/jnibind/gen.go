@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ClarkGuan/jni/classfile"
+)
+
+// 生成代码时使用的类信息
+type class struct {
+	*classfile.Class
+	goName  string // Go 类型名
+	varName string // 保存类和成员 ID 的变量名
+	members []*member
+}
+
+type member struct {
+	goName  string // 生成的 Go 函数名
+	idName  string // ID 在变量中的字段名
+	name    string
+	desc    string
+	static  bool
+	field   bool
+	ctor    bool
+	params  []string
+	ret     string
+	setter  string // 非 final 静态字段的设置函数名
+	isConst bool   // 生成为 const，不需要 ID
+	value   any
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	classes map[string]*class // 内部类名 -> 类
+}
+
+func generate(pkg string, list []*classfile.Class) []byte {
+	g := &generator{classes: make(map[string]*class)}
+	var classes []*class
+	// Init 是生成代码的入口，同名的类加上数字后缀
+	names := map[string]int{"Init": 1}
+	for _, c := range list {
+		// 不同包中的同名类加上数字后缀
+		name := goIdent(c.Name[strings.LastIndexByte(c.Name, '/')+1:])
+		if names[name]++; names[name] > 1 {
+			name += strconv.Itoa(names[name])
+		}
+		cls := newClass(c, name)
+		g.classes[c.Name] = cls
+		classes = append(classes, cls)
+	}
+
+	g.printf("// Code generated by jnibind. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", pkg)
+	mathImport := ""
+	for _, c := range classes {
+		for _, m := range c.members {
+			if m.isConst && !isFinite(m.value) {
+				mathImport = "\"math\"\n"
+			}
+		}
+	}
+	g.printf("import (\n\"errors\"\n\"fmt\"\n%s\n\"github.com/ClarkGuan/jni\"\n)\n\n", mathImport)
+
+	g.printf("// Init 解析所有类和成员，需要在使用生成的函数之前调用一次（如在 JNI_OnLoad 中）\n")
+	g.printf("func Init(env jni.Env) error {\nreturn errors.Join(\n")
+	for _, c := range classes {
+		g.printf("init%s(env),\n", c.goName)
+	}
+	g.printf(")\n}\n\n")
+
+	// 类的变量名都以 Class 结尾、初始化函数都以 init 开头，这里的名字不会和它们冲突
+	g.printf(`// 查找类并创建全局引用
+func jnibindClassRef(env jni.Env, name string) (jni.Jclass, error) {
+	local := env.FindClass(name)
+	if local == 0 {
+		if err := env.CheckException(); err != nil {
+			return 0, fmt.Errorf("找不到类 %%s: %%w", name, err)
+		}
+		return 0, fmt.Errorf("找不到类 %%s", name)
+	}
+	defer env.DeleteLocalRef(local)
+	return env.NewGlobalRef(local), nil
+}
+
+// 查找成员 ID，失败时记录错误
+func jnibindMemberID(env jni.Env, errs *[]error, id uintptr, class, name, sig string) uintptr {
+	if id == 0 {
+		err := env.CheckException()
+		*errs = append(*errs, fmt.Errorf("找不到 %%s.%%s%%s: %%v", class, name, sig, err))
+	}
+	return id
+}
+
+`)
+
+	for _, c := range classes {
+		g.class(c)
+	}
+	return g.buf.Bytes()
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func newClass(c *classfile.Class, name string) *class {
+	cls := &class{
+		Class:   c,
+		goName:  exported(name),
+		varName: unexported(name) + "Class",
+	}
+
+	used := make(map[string]int)
+	unique := func(name string) string {
+		used[name]++
+		if n := used[name]; n > 1 {
+			return name + strconv.Itoa(n)
+		}
+		return name
+	}
+
+	for _, f := range c.Fields {
+		// 只生成 public 静态字段；static final 基本类型和 String 常量生成为 const
+		if !f.Is(classfile.AccPublic) || !f.Is(classfile.AccStatic) || f.Is(classfile.AccSynthetic) {
+			continue
+		}
+		m := &member{name: f.Name, desc: f.Descriptor, static: true, field: true, ret: f.Descriptor}
+		m.goName = unique(cls.goName + "_" + goIdent(f.Name))
+		if f.Is(classfile.AccFinal) && f.ConstantValue != nil {
+			m.isConst, m.value = true, f.ConstantValue
+		} else {
+			m.idName = unique("f_" + goIdent(f.Name))
+			if !f.Is(classfile.AccFinal) {
+				m.setter = unique("Set" + m.goName)
+			}
+		}
+		cls.members = append(cls.members, m)
+	}
+
+	isAbstract := c.Is(classfile.AccAbstract) || c.Is(classfile.AccInterface)
+	for _, mt := range c.Methods {
+		if !mt.Is(classfile.AccPublic) || mt.Is(classfile.AccSynthetic) || mt.Is(classfile.AccBridge) || mt.Name == "<clinit>" {
+			continue
+		}
+		params, ret, err := classfile.ParseMethodDescriptor(mt.Descriptor)
+		if err != nil {
+			continue
+		}
+
+		m := &member{name: mt.Name, desc: mt.Descriptor, static: mt.Is(classfile.AccStatic), params: params, ret: ret}
+		switch {
+		case mt.Name == "<init>":
+			if isAbstract {
+				continue
+			}
+			m.ctor = true
+			m.goName = unique("New" + cls.goName)
+			m.idName = unique("m_new")
+		case m.static:
+			m.goName = unique(cls.goName + "_" + exported(goIdent(mt.Name)))
+			m.idName = unique("m_" + goIdent(mt.Name))
+		default:
+			m.goName = unique(exported(goIdent(mt.Name)))
+			m.idName = unique("m_" + goIdent(mt.Name))
+		}
+		cls.members = append(cls.members, m)
+	}
+	return cls
+}
+
+func (g *generator) class(c *class) {
+	javaName := strings.ReplaceAll(c.Name, "/", ".")
+	g.printf("// %s 对应 Java 类 %s\n", c.goName, javaName)
+	g.printf("type %s jni.Jobject\n\n", c.goName)
+
+	// 常量；NaN 和无穷大不能作为 Go 常量，生成为变量
+	var consts, vars []*member
+	for _, m := range c.members {
+		if m.isConst && isFinite(m.value) {
+			consts = append(consts, m)
+		} else if m.isConst {
+			vars = append(vars, m)
+		}
+	}
+	if len(consts) > 0 {
+		g.printf("const (\n")
+		for _, m := range consts {
+			g.printf("%s = %s\n", m.goName, constLiteral(m.value, m.desc))
+		}
+		g.printf(")\n\n")
+	}
+	if len(vars) > 0 {
+		g.printf("var (\n")
+		for _, m := range vars {
+			g.printf("%s = %s\n", m.goName, constLiteral(m.value, m.desc))
+		}
+		g.printf(")\n\n")
+	}
+
+	// ID
+	g.printf("var %s struct {\nclass jni.Jclass\n", c.varName)
+	for _, m := range c.members {
+		if m.isConst {
+			continue
+		}
+		if m.field {
+			g.printf("%s jni.JfieldID\n", m.idName)
+		} else {
+			g.printf("%s jni.JmethodID\n", m.idName)
+		}
+	}
+	g.printf("}\n\n")
+
+	g.printf("func init%s(env jni.Env) error {\n", c.goName)
+	g.printf("cls, err := jnibindClassRef(env, %q)\nif err != nil {\nreturn err\n}\n", c.Name)
+	g.printf("%s.class = cls\n\nvar errs []error\n", c.varName)
+	for _, m := range c.members {
+		if m.isConst {
+			continue
+		}
+		fn := "GetMethodID"
+		switch {
+		case m.field:
+			fn = "GetStaticFieldID"
+		case m.static:
+			fn = "GetStaticMethodID"
+		}
+		g.printf("%s.%s = jnibindMemberID(env, &errs, env.%s(cls, %q, %q), %q, %q, %q)\n",
+			c.varName, m.idName, fn, m.name, m.desc, c.Name, m.name, m.desc)
+	}
+	g.printf("return errors.Join(errs...)\n}\n\n")
+
+	for _, m := range c.members {
+		switch {
+		case m.isConst:
+		case m.field:
+			g.staticField(c, m)
+		case m.ctor:
+			g.constructor(c, m)
+		default:
+			g.method(c, m)
+		}
+	}
+}
+
+func (g *generator) staticField(c *class, m *member) {
+	kind := callKind(m.desc)
+	g.printf("// %s 读取静态字段 %s\n", m.goName, m.name)
+	g.printf("func %s(env jni.Env) %s {\n", m.goName, g.goType(m.desc))
+	g.printf("return %s(env.GetStatic%sField(%s.class, %s.%s))\n}\n\n", g.goType(m.desc), kind, c.varName, c.varName, m.idName)
+
+	if m.setter != "" {
+		g.printf("// %s 设置静态字段 %s\n", m.setter, m.name)
+		g.printf("func %s(env jni.Env, v %s) {\n", m.setter, g.goType(m.desc))
+		g.printf("env.SetStatic%sField(%s.class, %s.%s, %s(v))\n}\n\n", kind, c.varName, c.varName, m.idName, envType(m.desc))
+	}
+}
+
+func (g *generator) constructor(c *class, m *member) {
+	g.printf("// %s 调用构造函数 %s%s，返回局部引用\n", m.goName, c.goName, m.desc)
+	g.printf("func %s(env jni.Env%s) (%s, error) {\n", m.goName, g.paramList(m.params), c.goName)
+	g.printf("obj := env.NewObjectA(%s.class, %s.%s%s)\n", c.varName, c.varName, m.idName, argList(m.params))
+	g.printf("if obj == 0 {\nif err := env.CheckException(); err != nil {\nreturn 0, err\n}\n")
+	g.printf("return 0, errors.New(\"创建 %s 对象失败\")\n}\n", c.goName)
+	g.printf("return %s(obj), nil\n}\n\n", c.goName)
+}
+
+func (g *generator) method(c *class, m *member) {
+	var recv, target, call string
+	kind := callKind(m.ret)
+	if m.static {
+		target = c.varName + ".class"
+		call = "CallStatic" + kind + "MethodA"
+	} else {
+		recv = fmt.Sprintf("(o %s) ", c.goName)
+		target = "jni.Jobject(o)"
+		call = "Call" + kind + "MethodA"
+	}
+
+	g.printf("// %s 调用 %s%s\n", m.goName, m.name, m.desc)
+	if m.ret == "V" {
+		g.printf("func %s%s(env jni.Env%s) error {\n", recv, m.goName, g.paramList(m.params))
+		g.printf("env.%s(%s, %s.%s%s)\n", call, target, c.varName, m.idName, argList(m.params))
+		g.printf("return env.CheckException()\n}\n\n")
+		return
+	}
+
+	ret := g.goType(m.ret)
+	g.printf("func %s%s(env jni.Env%s) (%s, error) {\n", recv, m.goName, g.paramList(m.params), ret)
+	g.printf("ret := %s(env.%s(%s, %s.%s%s))\n", ret, call, target, c.varName, m.idName, argList(m.params))
+	g.printf("return ret, env.CheckException()\n}\n\n")
+}
+
+func (g *generator) paramList(params []string) string {
+	var sb strings.Builder
+	for i, p := range params {
+		fmt.Fprintf(&sb, ", p%d %s", i, g.goType(p))
+	}
+	return sb.String()
+}
+
+func argList(params []string) string {
+	var sb strings.Builder
+	for i, p := range params {
+		fmt.Fprintf(&sb, ", jni.ValueOf(%s(p%d))", envType(p), i)
+	}
+	return sb.String()
+}
+
+// Java 类型对应的 Go 类型，本次生成的类使用生成的 Go 类型
+func (g *generator) goType(desc string) string {
+	if c, ok := g.classes[strings.TrimSuffix(strings.TrimPrefix(desc, "L"), ";")]; ok && desc[0] == 'L' {
+		return c.goName
+	}
+
+	switch desc {
+	case "Ljava/lang/String;":
+		return "jni.Jstring"
+	case "Ljava/lang/Class;":
+		return "jni.Jclass"
+	case "[Z":
+		return "jni.JbooleanArray"
+	case "[B":
+		return "jni.JbyteArray"
+	case "[C":
+		return "jni.JcharArray"
+	case "[S":
+		return "jni.JshortArray"
+	case "[I":
+		return "jni.JintArray"
+	case "[J":
+		return "jni.JlongArray"
+	case "[F":
+		return "jni.JfloatArray"
+	case "[D":
+		return "jni.JdoubleArray"
+	}
+	if desc[0] == '[' {
+		return "jni.JobjectArray"
+	}
+	return envType(desc)
+}
+
+// 与 jni.Env 的 Call<Type>MethodA 等函数一致的 Go 类型
+func envType(desc string) string {
+	switch desc {
+	case "Z":
+		return "bool"
+	case "B":
+		return "byte"
+	case "C":
+		return "uint16"
+	case "S":
+		return "int16"
+	case "I":
+		return "int"
+	case "J":
+		return "int64"
+	case "F":
+		return "float32"
+	case "D":
+		return "float64"
+	}
+	return "jni.Jobject"
+}
+
+// Call<Type>MethodA、Get<Type>Field 中的 Type
+func callKind(desc string) string {
+	switch desc {
+	case "V":
+		return "Void"
+	case "Z":
+		return "Boolean"
+	case "B":
+		return "Byte"
+	case "C":
+		return "Char"
+	case "S":
+		return "Short"
+	case "I":
+		return "Int"
+	case "J":
+		return "Long"
+	case "F":
+		return "Float"
+	case "D":
+		return "Double"
+	}
+	return "Object"
+}
+
+func constLiteral(v any, desc string) string {
+	switch v := v.(type) {
+	case int32:
+		switch desc {
+		case "Z":
+			return strconv.FormatBool(v != 0)
+		case "B":
+			return fmt.Sprintf("byte(%d)", uint8(v))
+		}
+		return fmt.Sprintf("%s(%d)", envType(desc), v)
+	case int64:
+		return fmt.Sprintf("int64(%d)", v)
+	case float32:
+		return fmt.Sprintf("float32(%s)", floatLiteral(float64(v), 32))
+	case float64:
+		return fmt.Sprintf("float64(%s)", floatLiteral(v, 64))
+	case string:
+		return strconv.Quote(v)
+	}
+	return "nil"
+}
+
+// NaN 和无穷大使用 math 包中的函数，只能用于变量
+func floatLiteral(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "math.NaN()"
+	case math.IsInf(f, 1):
+		return "math.Inf(1)"
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+func isFinite(v any) bool {
+	switch v := v.(type) {
+	case float32:
+		return !math.IsNaN(float64(v)) && !math.IsInf(float64(v), 0)
+	case float64:
+		return !math.IsNaN(v) && !math.IsInf(v, 0)
+	}
+	return true
+}
+
+// Java 标识符中可能有 Go 不允许的 '$'
+func goIdent(name string) string {
+	return strings.ReplaceAll(name, "$", "_")
+}
+
+func exported(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func unexported(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
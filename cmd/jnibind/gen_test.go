@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"go/format"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ClarkGuan/jni/classfile"
+)
+
+var update = flag.Bool("update", false, "更新 testdata 中的 golden 文件")
+
+func TestGenerate(t *testing.T) {
+	classes, err := classfile.Load(filepath.Join("..", "..", "classfile", "testdata", "Demo.class"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := format.Source(generate("demo", classes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "demo.go.golden")
+	if *update {
+		if err = os.WriteFile(golden, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(src) != string(want) {
+		t.Errorf("生成的代码与 %s 不同，使用 -update 更新:\n%s", golden, src)
+	}
+}
+
+func TestGenerateNames(t *testing.T) {
+	const flags = classfile.AccPublic | classfile.AccStatic | classfile.AccFinal
+	classes := []*classfile.Class{
+		{AccessFlags: classfile.AccPublic, Name: "com/demo/Init", SuperName: "java/lang/Object",
+			Fields: []*classfile.Field{
+				{AccessFlags: flags, Name: "NAN", Descriptor: "F", ConstantValue: float32(math.NaN())},
+				{AccessFlags: flags, Name: "NEG", Descriptor: "D", ConstantValue: math.Inf(-1)},
+				{AccessFlags: flags, Name: "ONE", Descriptor: "D", ConstantValue: 1.0},
+			}},
+		{AccessFlags: classfile.AccPublic, Name: "com/demo/Main", SuperName: "java/lang/Object"},
+		{AccessFlags: classfile.AccPublic, Name: "com/other/Main", SuperName: "java/lang/Object"},
+	}
+	src, err := format.Source(generate("demo", classes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		// 类名 Init 和生成的 Init 函数冲突，同名的类加上数字后缀
+		"type Init2 jni.Jobject",
+		"type Main jni.Jobject",
+		"type Main2 jni.Jobject",
+		"func Init(env jni.Env) error {",
+		// NaN 和无穷大不能作为 Go 常量
+		"Init2_NAN = float32(math.NaN())",
+		"Init2_NEG = float64(math.Inf(-1))",
+		"\t\"math\"\n",
+		"Init2_ONE = float64(1.0)",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("生成的代码中没有 %q", s)
+		}
+	}
+}
+
+func TestIsAnonymous(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"com/demo/Main", false},
+		{"com/demo/Main$Inner", false},
+		{"com/demo/Main$1", true},
+		{"com/demo/Main$Inner$2", true},
+		{"com/demo/Main$1Local", true},
+		{"com/demo/Main$", true},
+	}
+	for _, tt := range tests {
+		if got := isAnonymous(tt.name); got != tt.want {
+			t.Errorf("isAnonymous(%q) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
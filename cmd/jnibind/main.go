@@ -0,0 +1,67 @@
+// jnibind 读取 .class/.jar 文件，为其中的 public 类生成 Go 绑定代码：
+//
+//	jnibind -p demo -o demo/bind.go -c com/demo/ build/classes
+//
+// 每个 Java 类生成一个 Go 类型（底层为 jni.Jobject），实例方法、构造函数（NewXxx）、
+// 静态方法和静态字段访问函数（Xxx_Name）都通过预先解析的 ID 调用，常量生成为 Go const。
+// 使用前需要调用生成的 Init(env) 解析所有类和成员。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"strings"
+
+	"github.com/ClarkGuan/jni/classfile"
+)
+
+func main() {
+	var pkg, output, prefix string
+	flag.StringVar(&pkg, "p", "bind", "指定 Go package 名称")
+	flag.StringVar(&output, "o", "", "输出文件，默认为标准输出")
+	flag.StringVar(&prefix, "c", "", "只处理类名以此为前缀的类，如 com/demo/")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "用法: jnibind [-p package] [-o output] [-c prefix] <.class|.jar|目录>...")
+		os.Exit(2)
+	}
+
+	classes, err := classfile.Load(flag.Args()...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var selected []*classfile.Class
+	for _, c := range classes {
+		if strings.HasPrefix(c.Name, prefix) && c.Is(classfile.AccPublic) && !isAnonymous(c.Name) {
+			selected = append(selected, c)
+		}
+	}
+
+	src, err := format.Source(generate(pkg, selected))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "格式化生成的代码失败:", err)
+		os.Exit(1)
+	}
+
+	if output == "" {
+		os.Stdout.Write(src)
+	} else if err = os.WriteFile(output, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// 匿名类（如 Main$1）无法在 Java 代码之外使用
+func isAnonymous(name string) bool {
+	for _, s := range strings.Split(name, "$")[1:] {
+		if s == "" || s[0] >= '0' && s[0] <= '9' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+func generate(pkg string, ifaces []*iface) []byte {
+	var buf bytes.Buffer
+	p := func(format string, args ...any) {
+		fmt.Fprintf(&buf, format, args...)
+	}
+
+	p("// Code generated by jniimpl. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	p("import (\n\"fmt\"\n\n\"github.com/ClarkGuan/jni\"\n)\n\n")
+
+	// 辅助函数生成在用户的包中，使用 jniimpl 前缀避免与其他代码冲突
+	p(`// 取出接口值中的 Java 对象，参数必须是生成的实现（或 nil）
+func jniimplJavaObject(v any) (jni.Jobject, error) {
+	if v == nil {
+		return 0, nil
+	}
+	if o, ok := v.(interface{ JavaObject() jni.Jobject }); ok {
+		return o.JavaObject(), nil
+	}
+	return 0, fmt.Errorf("%%T 不是 Java 对象", v)
+}
+
+`)
+
+	for _, it := range ifaces {
+		impl := lowerFirst(it.name) + "Impl"
+		methods := lowerFirst(it.name) + "Methods"
+		javaName := strings.ReplaceAll(it.class, "/", ".")
+
+		p("// New%s 把 %s 对象包装为 %s，obj 为 0 时返回 nil。\n", it.name, javaName, it.name)
+		p("// 返回值只能在 env 所属的线程中使用，它直接使用 obj，不会创建新的引用。\n")
+		p("//\n")
+		p("// 返回 Java 对象的方法每次都会创建新的局部引用。在循环中或附加到 JVM 的线程中\n")
+		p("// （局部引用不会自动释放）不再使用时需要调用 Release，否则可能耗尽局部引用表。\n")
+		p("func New%s(env jni.Env, obj jni.Jobject) %s {\n", it.name, it.name)
+		p("if obj == 0 {\nreturn nil\n}\nreturn &%s{env: env, obj: obj}\n}\n\n", impl)
+
+		p("// Resolve%s 解析 %s 的所有方法，可以在加载时调用以便提前发现错误\n", it.name, javaName)
+		p("func Resolve%s(env jni.Env) error {\n", it.name)
+		for _, m := range it.methods {
+			p("if err := %s.%s.Resolve(env); err != nil {\nreturn err\n}\n", methods, m.goName)
+		}
+		p("return nil\n}\n\n")
+
+		p("var %s = struct {\n", methods)
+		for _, m := range it.methods {
+			p("%s *jni.Method[%s]\n", m.goName, m.callType())
+		}
+		p("}{\n")
+		for _, m := range it.methods {
+			p("%s: jni.NewMethod[%s](%q, %q, %q),\n", m.goName, m.callType(), it.class, m.name, m.desc)
+		}
+		p("}\n\n")
+
+		p("type %s struct {\nenv jni.Env\nobj jni.Jobject\n}\n\n", impl)
+		p("// JavaObject 返回包装的 Java 对象\nfunc (o *%s) JavaObject() jni.Jobject {\nreturn o.obj\n}\n\n", impl)
+		p("// Release 删除包装的 Java 对象的局部引用，之后不能再使用 o\nfunc (o *%s) Release() {\no.env.DeleteLocalRef(o.obj)\no.obj = 0\n}\n\n", impl)
+
+		for _, m := range it.methods {
+			generateMethod(p, impl, methods, m)
+		}
+	}
+	return buf.Bytes()
+}
+
+// Method[R] 中的 R
+func (m *method) callType() string {
+	switch {
+	case m.ret == nil:
+		return "struct{}"
+	case m.ret.kind == 'L':
+		return "jni.Jobject"
+	}
+	return m.ret.goType
+}
+
+func generateMethod(p func(string, ...any), impl, methods string, m *method) {
+	var params, results []string
+	for i, t := range m.params {
+		params = append(params, fmt.Sprintf("p%d %s", i, t.goType))
+	}
+	if m.ret != nil {
+		results = append(results, "ret "+m.ret.goType)
+	}
+	if m.hasError {
+		results = append(results, "err error")
+	}
+	result := ""
+	if len(results) > 0 {
+		result = "(" + strings.Join(results, ", ") + ")"
+	}
+
+	// 出错时返回错误或者 panic
+	var fail string
+	if m.hasError {
+		fail = "return\n"
+		if m.ret != nil {
+			fail = "return ret, err\n"
+		}
+	} else {
+		fail = "panic(err)\n"
+	}
+
+	p("// %s 调用 %s%s\n", m.goName, m.name, m.desc)
+	p("func (o *%s) %s(%s) %s {\n", impl, m.goName, strings.Join(params, ", "), result)
+	p("env := o.env\n")
+	if !m.hasError {
+		p("var err error\n")
+	}
+
+	var args []string
+	for i, t := range m.params {
+		arg := fmt.Sprintf("p%d", i)
+		switch {
+		case t.kind == 's':
+			p("a%d, err := jni.EncodeString(env, p%d)\nif err != nil {\n%s}\ndefer env.DeleteLocalRef(a%d)\n", i, i, fail, i)
+			arg = fmt.Sprintf("a%d", i)
+		case t.iface != "":
+			p("a%d, err := jniimplJavaObject(p%d)\nif err != nil {\n%s}\n", i, i, fail)
+			arg = fmt.Sprintf("a%d", i)
+		case t.kind == 'L':
+			arg = fmt.Sprintf("jni.Jobject(p%d)", i)
+		}
+		args = append(args, arg)
+	}
+
+	call := fmt.Sprintf("%s.%s.Call(env, o.obj", methods, m.goName)
+	if len(args) > 0 {
+		call += ", " + strings.Join(args, ", ")
+	}
+	call += ")"
+
+	switch {
+	case m.ret == nil:
+		p("if _, err = %s; err != nil {\n%s}\n", call, fail)
+	case m.ret.iface != "":
+		p("r, err := %s\nif err != nil {\n%s}\n", call, fail)
+		p("ret = New%s(env, r)\n", m.ret.iface)
+	case m.ret.kind == 'L':
+		p("r, err := %s\nif err != nil {\n%s}\n", call, fail)
+		p("ret = %s(r)\n", m.ret.goType)
+	default:
+		p("if ret, err = %s; err != nil {\n%s}\n", call, fail)
+	}
+	p("return\n}\n\n")
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"go/format"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "更新 testdata 中的 golden 文件")
+
+func TestGenerate(t *testing.T) {
+	pkg, ifaces, errs := scan(filepath.Join("testdata", "builder"))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	src, err := format.Source(generate(pkg, ifaces))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "builder_jni.go.golden")
+	if *update {
+		if err = os.WriteFile(golden, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(src) != string(want) {
+		t.Errorf("生成的代码与 %s 不同，使用 -update 更新:\n%s", golden, src)
+	}
+}
@@ -0,0 +1,60 @@
+// jniimpl 为 Go 包中标注了 Java 类名的接口生成基于 jni.Env 的实现：
+//
+//	//jni:class java.lang.StringBuilder
+//	type StringBuilder interface {
+//		Append(s string) StringBuilder
+//		//jni:method length ()I
+//		Length() (int32, error)
+//	}
+//
+// 执行 jniimpl -o builder_jni.go . 后生成 NewStringBuilder(env, obj) StringBuilder，
+// 方法描述符由 Go 签名推导（方法名为首字母小写的 Go 方法名），也可以用 //jni:method name [descriptor] 指定。
+//
+// 参数和返回值可以是 Go 基本类型、string、jni.Jobject 等引用类型以及同一个包中标注过的接口。
+// 最后一个返回值为 error 时，Java 异常以错误返回，否则会 panic。
+//
+// 生成的实现还有 JavaObject() jni.Jobject 和 Release() 方法，接口中可以声明它们。
+// 返回 Java 对象的方法（如 Append）每次都会创建新的局部引用，在循环中使用时需要调用 Release 删除。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+)
+
+func main() {
+	var output string
+	flag.StringVar(&output, "o", "", "输出文件，默认为标准输出")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: jniimpl [-o output] <Go 包目录>")
+		os.Exit(2)
+	}
+
+	pkg, ifaces, errs := scan(flag.Arg(0))
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(1)
+	}
+	if len(ifaces) == 0 {
+		fmt.Fprintln(os.Stderr, "没有找到标注 //jni:class 的接口")
+		os.Exit(1)
+	}
+
+	src, err := format.Source(generate(pkg, ifaces))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "格式化生成的代码失败:", err)
+		os.Exit(1)
+	}
+	if output == "" {
+		os.Stdout.Write(src)
+	} else if err = os.WriteFile(output, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ClarkGuan/jni/classfile"
+)
+
+const jniPath = "github.com/ClarkGuan/jni"
+
+// 标注了 //jni:class 的接口
+type iface struct {
+	name    string
+	class   string // 内部类名
+	methods []*method
+}
+
+type method struct {
+	pos      token.Position
+	goName   string
+	name     string // Java 方法名
+	desc     string
+	params   []typ
+	ret      *typ // 没有返回值时为 nil
+	hasError bool // 最后一个返回值为 error
+}
+
+// Go 类型及其对应的 Java 类型
+type typ struct {
+	goType string // 生成代码中的 Go 类型
+	desc   string
+	iface  string // 接口类型的名称
+	kind   byte   // 基本类型为描述符字符，string 为 's'，引用为 'L'
+}
+
+func scan(dir string) (pkg string, ifaces []*iface, errs []error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, []error{err}
+	}
+
+	fset := token.NewFileSet()
+	type pending struct {
+		spec *ast.TypeSpec
+		it   *ast.InterfaceType
+		iface
+		jni string
+	}
+	var list []*pending
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pkg = f.Name.Name
+
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				it, ok := ts.Type.(*ast.InterfaceType)
+				if !ok {
+					continue
+				}
+				doc := ts.Doc
+				if doc == nil && len(gd.Specs) == 1 {
+					doc = gd.Doc
+				}
+				class := directive(doc, "//jni:class ")
+				if class == "" {
+					continue
+				}
+				list = append(list, &pending{
+					spec:  ts,
+					it:    it,
+					iface: iface{name: ts.Name.Name, class: strings.ReplaceAll(class, ".", "/")},
+					jni:   importName(f, jniPath),
+				})
+			}
+		}
+	}
+
+	// 先收集所有接口，方法中可以引用其他接口
+	classes := make(map[string]string)
+	for _, p := range list {
+		classes[p.name] = p.class
+	}
+	for _, p := range list {
+		r := &resolver{fset: fset, jni: p.jni, classes: classes}
+		for _, field := range p.it.Methods.List {
+			ft, ok := field.Type.(*ast.FuncType)
+			if !ok || len(field.Names) == 0 {
+				errs = append(errs, fmt.Errorf("%s: %s 不能嵌入其他接口", fset.Position(field.Pos()), p.name))
+				continue
+			}
+			if builtin, err := r.builtin(field.Names[0].Name, ft, field.Doc); builtin || err != nil {
+				if err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			m, err := r.method(field.Names[0].Name, ft, field.Doc)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			p.methods = append(p.methods, m)
+		}
+		ifaces = append(ifaces, &p.iface)
+	}
+	return pkg, ifaces, errs
+}
+
+func directive(doc *ast.CommentGroup, prefix string) string {
+	if doc == nil {
+		return ""
+	}
+	for _, c := range doc.List {
+		if s, ok := strings.CutPrefix(c.Text, prefix); ok {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+func importName(f *ast.File, path string) string {
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == path {
+			if imp.Name != nil {
+				return imp.Name.Name
+			}
+			return path[strings.LastIndexByte(path, '/')+1:]
+		}
+	}
+	return ""
+}
+
+type resolver struct {
+	fset    *token.FileSet
+	jni     string            // 文件中 jni 包的名称
+	classes map[string]string // 接口名 -> 内部类名
+}
+
+// 生成的实现自带 JavaObject 和 Release 方法，接口中声明它们时不对应 Java 方法
+var builtins = map[string]string{
+	"JavaObject": "func() jni.Jobject",
+	"Release":    "func()",
+}
+
+// 判断 name 是否为生成的方法，签名不一致时返回错误
+func (r *resolver) builtin(name string, ft *ast.FuncType, doc *ast.CommentGroup) (bool, error) {
+	want, ok := builtins[name]
+	if !ok || directive(doc, "//jni:method ") != "" {
+		return false, nil
+	}
+
+	sig := "func()"
+	if ft.Results != nil && len(ft.Results.List) > 0 {
+		var results []string
+		for _, field := range ft.Results.List {
+			for range max(len(field.Names), 1) {
+				results = append(results, exprString(field.Type))
+			}
+		}
+		sig += " " + strings.Join(results, ", ")
+	}
+	if len(ft.Params.List) > 0 || strings.Replace(sig, r.jni+".", "jni.", 1) != want {
+		return false, fmt.Errorf("%s: %s 是生成的方法，签名应为 %s（对应 Java 方法时需要使用 //jni:method）",
+			r.fset.Position(ft.Pos()), name, want)
+	}
+	return true, nil
+}
+
+func (r *resolver) method(name string, ft *ast.FuncType, doc *ast.CommentGroup) (*method, error) {
+	m := &method{pos: r.fset.Position(ft.Pos()), goName: name}
+	fail := func(format string, args ...any) error {
+		return fmt.Errorf("%s: %s: %s", m.pos, name, fmt.Sprintf(format, args...))
+	}
+
+	for _, field := range ft.Params.List {
+		t, err := r.typeOf(field.Type)
+		if err != nil {
+			return nil, fail("%v", err)
+		}
+		for range max(len(field.Names), 1) {
+			m.params = append(m.params, *t)
+		}
+	}
+
+	var results []ast.Expr
+	if ft.Results != nil {
+		for _, field := range ft.Results.List {
+			for range max(len(field.Names), 1) {
+				results = append(results, field.Type)
+			}
+		}
+	}
+	if n := len(results); n > 0 {
+		if id, ok := results[n-1].(*ast.Ident); ok && id.Name == "error" {
+			m.hasError, results = true, results[:n-1]
+		}
+	}
+	switch len(results) {
+	case 0:
+	case 1:
+		t, err := r.typeOf(results[0])
+		if err != nil {
+			return nil, fail("%v", err)
+		}
+		m.ret = t
+	default:
+		return nil, fail("最多只能有一个返回值和一个 error")
+	}
+
+	// 默认的方法名和描述符
+	m.name = string(unicode.ToLower(rune(name[0]))) + name[1:]
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, p := range m.params {
+		sb.WriteString(p.desc)
+	}
+	sb.WriteByte(')')
+	if m.ret == nil {
+		sb.WriteByte('V')
+	} else {
+		sb.WriteString(m.ret.desc)
+	}
+	m.desc = sb.String()
+
+	if d := directive(doc, "//jni:method "); d != "" {
+		fields := strings.Fields(d)
+		m.name = fields[0]
+		if len(fields) > 1 {
+			m.desc = fields[1]
+			if err := m.checkDescriptor(); err != nil {
+				return nil, fail("%v", err)
+			}
+		}
+	}
+	return m, nil
+}
+
+// 检查指定的描述符与 Go 签名是否一致
+func (m *method) checkDescriptor() error {
+	params, ret, err := classfile.ParseMethodDescriptor(m.desc)
+	if err != nil {
+		return err
+	}
+	if len(params) != len(m.params) {
+		return fmt.Errorf("描述符 %s 有 %d 个参数，Go 方法有 %d 个", m.desc, len(params), len(m.params))
+	}
+	for i, p := range params {
+		if !m.params[i].accepts(p) {
+			return fmt.Errorf("第 %d 个参数的 Go 类型 %s 与 Java 类型 %s 不匹配", i+1, m.params[i].goType, p)
+		}
+	}
+	switch {
+	case ret == "V" && m.ret != nil:
+		return fmt.Errorf("描述符 %s 没有返回值", m.desc)
+	case ret != "V" && m.ret == nil:
+		return fmt.Errorf("描述符 %s 有返回值，Go 方法没有", m.desc)
+	case ret != "V" && !m.ret.accepts(ret):
+		return fmt.Errorf("返回值的 Go 类型 %s 与 Java 类型 %s 不匹配", m.ret.goType, ret)
+	}
+	return nil
+}
+
+// 判断 Go 类型能否表示描述符为 desc 的 Java 值
+func (t *typ) accepts(desc string) bool {
+	switch t.kind {
+	case 's':
+		return desc == "Ljava/lang/String;"
+	case 'L':
+		return desc[0] == 'L' || desc[0] == '['
+	}
+	return desc == string(t.kind)
+}
+
+var basicTypes = map[string]byte{
+	"bool": 'Z', "int8": 'B', "byte": 'B', "uint8": 'B', "uint16": 'C', "int16": 'S',
+	"int32": 'I', "int": 'I', "int64": 'J', "float32": 'F', "float64": 'D',
+}
+
+// jni 包中的引用类型
+var refTypes = map[string]string{
+	"Jobject":       "Ljava/lang/Object;",
+	"Jstring":       "Ljava/lang/String;",
+	"Jclass":        "Ljava/lang/Class;",
+	"Jthrowable":    "Ljava/lang/Throwable;",
+	"JbooleanArray": "[Z",
+	"JbyteArray":    "[B",
+	"JcharArray":    "[C",
+	"JshortArray":   "[S",
+	"JintArray":     "[I",
+	"JlongArray":    "[J",
+	"JfloatArray":   "[F",
+	"JdoubleArray":  "[D",
+	"JobjectArray":  "[Ljava/lang/Object;",
+}
+
+func (r *resolver) typeOf(expr ast.Expr) (*typ, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if p, ok := basicTypes[e.Name]; ok {
+			return &typ{goType: e.Name, desc: string(p), kind: p}, nil
+		}
+		if e.Name == "string" {
+			return &typ{goType: "string", desc: "Ljava/lang/String;", kind: 's'}, nil
+		}
+		if class, ok := r.classes[e.Name]; ok {
+			return &typ{goType: e.Name, desc: "L" + class + ";", iface: e.Name, kind: 'L'}, nil
+		}
+
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && r.jni != "" && x.Name == r.jni {
+			if desc, ok := refTypes[e.Sel.Name]; ok {
+				return &typ{goType: "jni." + e.Sel.Name, desc: desc, kind: 'L'}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("不支持的类型 %s", exprString(expr))
+}
+
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(e.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(e.Elt)
+	}
+	return fmt.Sprintf("%T", expr)
+}
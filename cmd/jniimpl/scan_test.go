@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanError(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"Release() error", "Release 是生成的方法，签名应为 func()"},
+		{"JavaObject() uintptr", "JavaObject 是生成的方法，签名应为 func() jni.Jobject"},
+		{"Get(m map[string]int) int32", "不支持的类型 *ast.MapType"},
+		{"Pair() (int32, int32)", "最多只能有一个返回值和一个 error"},
+		{"//jni:method length ()J\nLength() int32", "返回值的 Go 类型 int32 与 Java 类型 J 不匹配"},
+		{"//jni:method charAt (IJ)C\nCharAt(i int32) uint16", "描述符 (IJ)C 有 2 个参数，Go 方法有 1 个"},
+		{"//jni:method setLength (I)V\nSetLength(n int32) int32", "描述符 (I)V 没有返回值"},
+		{"fmt.Stringer", "不能嵌入其他接口"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		src := "package p\n\nimport (\n\t\"fmt\"\n\n\t\"github.com/ClarkGuan/jni\"\n)\n\nvar _ fmt.Stringer\nvar _ jni.Env\n\n" +
+			"//jni:class java.lang.StringBuilder\ntype StringBuilder interface {\n" + tt.method + "\n}\n"
+		if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, errs := scan(dir)
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want) {
+			t.Errorf("%q: 错误为 %v，期望包含 %q", tt.method, errs, tt.want)
+		}
+	}
+}
+
+func TestScanBuiltin(t *testing.T) {
+	_, ifaces, errs := scan(filepath.Join("testdata", "builder"))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	for _, m := range ifaces[0].methods {
+		if _, ok := builtins[m.goName]; ok {
+			t.Errorf("%s 是生成的方法，不应该对应 Java 方法", m.goName)
+		}
+	}
+}
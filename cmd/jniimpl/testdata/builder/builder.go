@@ -0,0 +1,23 @@
+package builder
+
+import "github.com/ClarkGuan/jni"
+
+//jni:class java.lang.StringBuilder
+type StringBuilder interface {
+	Append(s string) StringBuilder
+	//jni:method append (I)Ljava/lang/StringBuilder;
+	AppendInt(i int32) StringBuilder
+	//jni:method insert (ILjava/lang/CharSequence;)Ljava/lang/StringBuilder;
+	Insert(offset int32, s CharSequence) (StringBuilder, error)
+	//jni:method length ()I
+	Length() (int32, error)
+	SetLength(n int32) error
+	ToString() jni.Jstring
+	JavaObject() jni.Jobject
+	Release()
+}
+
+//jni:class java.lang.CharSequence
+type CharSequence interface {
+	CharAt(index int32) uint16
+}
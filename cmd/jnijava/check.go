@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/ClarkGuan/jni/mangle"
+)
+
+// 检查所有 native 函数的签名和导出名称
+func check(natives []*native) []error {
+	var errs []error
+	overloads := make(map[string][]*native)
+	for _, n := range natives {
+		key := n.class + "#" + n.method
+		overloads[key] = append(overloads[key], n)
+		errs = append(errs, n.checkSignature()...)
+	}
+
+	for _, n := range natives {
+		list := overloads[n.class+"#"+n.method]
+		for _, other := range list {
+			if other == n {
+				break
+			}
+			if other.methodDescriptor() == n.methodDescriptor() {
+				errs = append(errs, fmt.Errorf("%s: %s 与 %s 重复", n.pos, n, other.pos))
+			}
+		}
+
+		long, _ := mangle.LongName(n.class, n.method, n.methodDescriptor())
+		// 嵌套类的内部类名是 Outer$Inner，符号名中的 '$' 编码为 _00024 而不是 '_'
+		hint := ""
+		if strings.Contains(long, "_00024") {
+			hint = "，嵌套类名中的 $ 编码为 _00024"
+		}
+		switch {
+		case n.export == long:
+		case len(list) > 1:
+			errs = append(errs, fmt.Errorf("%s: %s 是重载方法，导出名称应为 %s%s", n.pos, n, long, hint))
+		case n.export != mangle.ShortName(n.class, n.method):
+			errs = append(errs, fmt.Errorf("%s: %s 的导出名称应为 %s（或 %s）%s", n.pos, n, mangle.ShortName(n.class, n.method), long, hint))
+		}
+	}
+	return errs
+}
+
+func (n *native) String() string {
+	return strings.ReplaceAll(n.class, "/", ".") + "." + n.method + n.methodDescriptor()
+}
+
+func (n *native) methodDescriptor() string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, p := range n.params {
+		sb.WriteString(p.desc)
+	}
+	sb.WriteByte(')')
+	sb.WriteString(n.ret)
+	return sb.String()
+}
+
+// 检查 Go 函数的参数和返回值：env、this/clazz，然后是 Java 方法的参数
+func (n *native) checkSignature() []error {
+	var types []ast.Expr
+	for _, field := range n.fn.Type.Params.List {
+		for range max(len(field.Names), 1) {
+			types = append(types, field.Type)
+		}
+	}
+
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s: %s", n.pos, n.fn.Name.Name, fmt.Sprintf(format, args...)))
+	}
+
+	if want := 2 + len(n.params); len(types) != want {
+		fail("需要 %d 个参数（env、%s 以及 Java 方法的 %d 个参数），实际为 %d 个", want, n.selfName(), len(n.params), len(types))
+		return errs
+	}
+
+	if !allowed(types[0], []string{"uintptr", "*C.JNIEnv"}) {
+		fail("第 1 个参数 env 的类型应为 uintptr 或 *C.JNIEnv，实际为 %s", typeString(types[0]))
+	}
+	if !allowed(types[1], []string{"uintptr", "C.jobject", "C.jclass"}) {
+		fail("第 2 个参数 %s 的类型应为 uintptr、C.jobject 或 C.jclass，实际为 %s", n.selfName(), typeString(types[1]))
+	}
+	for i, p := range n.params {
+		if want := goTypes(p.desc); !allowed(types[i+2], want) {
+			fail("参数 %s 是 Java 的 %s，Go 类型应为 %s，实际为 %s", p.name, p.java, strings.Join(want, "、"), typeString(types[i+2]))
+		}
+	}
+
+	var results []ast.Expr
+	if n.fn.Type.Results != nil {
+		for _, field := range n.fn.Type.Results.List {
+			for range max(len(field.Names), 1) {
+				results = append(results, field.Type)
+			}
+		}
+	}
+	switch {
+	case n.ret == "V" && len(results) != 0:
+		fail("Java 方法没有返回值，Go 函数不能有返回值")
+	case n.ret != "V" && len(results) != 1:
+		fail("Go 函数需要一个返回值")
+	case n.ret != "V":
+		if want := goTypes(n.ret); !allowed(results[0], want) {
+			fail("返回值的 Go 类型应为 %s，实际为 %s", strings.Join(want, "、"), typeString(results[0]))
+		}
+	}
+	return errs
+}
+
+func (n *native) selfName() string {
+	if n.static {
+		return "clazz"
+	}
+	return "this"
+}
+
+func allowed(expr ast.Expr, types []string) bool {
+	got := typeString(expr)
+	for _, t := range types {
+		if got == t {
+			return true
+		}
+	}
+	return false
+}
+
+// 字段描述符可以使用的 Go 类型。cgo 导出函数中只能使用基本类型和 C 类型（不能使用 jni.Jobject 等），
+// int 为 64 位，不能用于 Java 的 int
+func goTypes(desc string) []string {
+	switch desc {
+	case "Z":
+		return []string{"bool", "uint8", "C.jboolean"}
+	case "B":
+		return []string{"int8", "byte", "uint8", "C.jbyte"}
+	case "C":
+		return []string{"uint16", "C.jchar"}
+	case "S":
+		return []string{"int16", "C.jshort"}
+	case "I":
+		return []string{"int32", "C.jint"}
+	case "J":
+		return []string{"int64", "C.jlong"}
+	case "F":
+		return []string{"float32", "C.jfloat"}
+	case "D":
+		return []string{"float64", "C.jdouble"}
+	}
+
+	types := []string{"uintptr", "C.jobject"}
+	var specific string
+	switch desc {
+	case "Ljava/lang/String;":
+		specific = "string"
+	case "Ljava/lang/Class;":
+		specific = "class"
+	case "Ljava/lang/Throwable;":
+		specific = "throwable"
+	case "[Z":
+		specific = "booleanArray"
+	case "[B":
+		specific = "byteArray"
+	case "[C":
+		specific = "charArray"
+	case "[S":
+		specific = "shortArray"
+	case "[I":
+		specific = "intArray"
+	case "[J":
+		specific = "longArray"
+	case "[F":
+		specific = "floatArray"
+	case "[D":
+		specific = "doubleArray"
+	default:
+		if desc[0] == '[' {
+			specific = "objectArray"
+		}
+	}
+	if specific != "" {
+		types = append(types, "C.j"+specific)
+	}
+	if desc[0] == '[' {
+		types = append(types, "C.jarray")
+	}
+	return types
+}
+
+func typeString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return typeString(e.X) + "." + e.Sel.Name
+	case *ast.StarExpr:
+		return "*" + typeString(e.X)
+	case *ast.ArrayType:
+		return "[]" + typeString(e.Elt)
+	}
+	return fmt.Sprintf("%T", expr)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ClarkGuan/jni/classfile"
+)
+
+// 为每个类生成一个 Java 源文件
+func writeJava(dir, lib string, natives []*native) error {
+	byClass := make(map[string][]*native)
+	for _, n := range natives {
+		byClass[n.class] = append(byClass[n.class], n)
+	}
+
+	classes := make([]string, 0, len(byClass))
+	for class := range byClass {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	for _, class := range classes {
+		path := filepath.Join(dir, filepath.FromSlash(class)+".java")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, javaSource(class, lib, byClass[class]), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func javaSource(class, lib string, natives []*native) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by jnijava. DO NOT EDIT.\n\n")
+
+	simple := class
+	if i := strings.LastIndexByte(class, '/'); i >= 0 {
+		fmt.Fprintf(&buf, "package %s;\n\n", strings.ReplaceAll(class[:i], "/", "."))
+		simple = class[i+1:]
+	}
+
+	fmt.Fprintf(&buf, "public class %s {\n", simple)
+	fmt.Fprintf(&buf, "    static {\n        System.loadLibrary(%q);\n    }\n", lib)
+	for _, n := range natives {
+		params := make([]string, len(n.params))
+		for i, p := range n.params {
+			params[i] = p.java + " " + p.name
+		}
+
+		modifiers := "public native"
+		if n.static {
+			modifiers = "public static native"
+		}
+		fmt.Fprintf(&buf, "\n    // 由 Go 函数 %s 实现\n", n.fn.Name.Name)
+		fmt.Fprintf(&buf, "    %s %s %s(%s);\n", modifiers, strings.ReplaceAll(classfile.JavaName(n.ret), "$", "."), n.method, strings.Join(params, ", "))
+	}
+	fmt.Fprintf(&buf, "}\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "更新 testdata 中的 golden 文件")
+
+func TestParseDirective(t *testing.T) {
+	tests := []struct {
+		directive string
+		class     string
+		method    string
+		static    bool
+		params    []string
+		ret       string
+	}{
+		{"static com.demo.Main#hash(byte[] data) long", "com/demo/Main", "hash", true, []string{"[B"}, "J"},
+		{"com.demo.Main#run() void", "com/demo/Main", "run", false, nil, "V"},
+		{"Main#size(int, long) int", "Main", "size", false, []string{"I", "J"}, "I"},
+		// java.lang 中的类可以省略包名，其他不带包名的类属于同一个包
+		{"com.demo.Main#put(String s, Object o, User u) Integer", "com/demo/Main", "put", false,
+			[]string{"Ljava/lang/String;", "Ljava/lang/Object;", "Lcom/demo/User;"}, "Ljava/lang/Integer;"},
+		{"com.demo.Main#all(java.util.List[][] l) String[]", "com/demo/Main", "all", false,
+			[]string{"[[Ljava/util/List;"}, "[Ljava/lang/String;"},
+		// 嵌套类
+		{"com.demo.Main#nested(Outer.Inner a, Outer$Inner b, com.demo.Outer.Inner c, Character.UnicodeBlock d) java.util.Map.Entry",
+			"com/demo/Main", "nested", false,
+			[]string{"Lcom/demo/Outer$Inner;", "Lcom/demo/Outer$Inner;", "Lcom/demo/Outer$Inner;", "Ljava/lang/Character$UnicodeBlock;"},
+			"Ljava/util/Map$Entry;"},
+	}
+	for _, tt := range tests {
+		n, err := parseDirective(tt.directive)
+		if err != nil {
+			t.Errorf("%q: %v", tt.directive, err)
+			continue
+		}
+		var params []string
+		for _, p := range n.params {
+			params = append(params, p.desc)
+		}
+		if n.class != tt.class || n.method != tt.method || n.static != tt.static || !slices.Equal(params, tt.params) || n.ret != tt.ret {
+			t.Errorf("%q = %s %s %t %q %s", tt.directive, n.class, n.method, n.static, params, n.ret)
+		}
+	}
+}
+
+func TestParseDirectiveError(t *testing.T) {
+	tests := []struct {
+		directive, want string
+	}{
+		{"com.demo.Main.hash() long", "无效的指令"},
+		{"com.demo.Main#1hash() long", "无效的方法名"},
+		{"com.demo.Main#hash(int a b) long", "无效的参数"},
+		{"com.demo.Main#hash(void v) long", "类型不能是 void"},
+		{"com.demo.Main#hash(int)", "缺少返回值类型"},
+		{"com.demo.Main#hash() void[]", "无效的类型 void[]"},
+		{"com.demo.Main#hash(java.util.2List l) void", "无效的类型"},
+		{"com.demo.Outer.Inner#run() void", "不支持嵌套类"},
+	}
+	for _, tt := range tests {
+		if _, err := parseDirective(tt.directive); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: 错误为 %v，期望包含 %q", tt.directive, err, tt.want)
+		}
+	}
+}
+
+// 在临时目录中写入 Go 文件并扫描
+func scanSource(t *testing.T, src string) ([]*native, []error) {
+	t.Helper()
+	dir := t.TempDir()
+	src = "package main\n\nimport \"C\"\n\n" + src
+	if err := os.WriteFile(filepath.Join(dir, "native.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return scan(dir)
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string // 为空时不应该有错误
+	}{
+		{`//jni:native static com.demo.Main#hash(byte[] data) long
+//export Java_com_demo_Main_hash
+func f(env uintptr, clazz uintptr, data C.jbyteArray) int64 { return 0 }`, ""},
+		{`//jni:native static com.demo.Main#hash(byte[] data) long
+//export Java_com_demo_Main_hash___3B
+func f(env *C.JNIEnv, clazz C.jclass, data C.jarray) C.jlong { return 0 }`, ""},
+		{`//jni:native com.demo.Main#hash(byte[] data) long
+func f(env uintptr, this uintptr, data uintptr) int64 { return 0 }`, "缺少 //export 指令"},
+		{`//jni:native com.demo.Main#hash(byte[] data) long
+//export Java_com_demo_Main_hash
+func f(env uintptr, data uintptr) int64 { return 0 }`, "需要 3 个参数（env、this 以及 Java 方法的 1 个参数），实际为 2 个"},
+		{`//jni:native com.demo.Main#hash(int n) long
+//export Java_com_demo_Main_hash
+func f(env int, this uintptr, n int32) int64 { return 0 }`, "第 1 个参数 env 的类型应为 uintptr 或 *C.JNIEnv，实际为 int"},
+		{`//jni:native com.demo.Main#hash(int n) long
+//export Java_com_demo_Main_hash
+func f(env uintptr, this uintptr, n int) int64 { return 0 }`, "参数 n 是 Java 的 int，Go 类型应为 int32、C.jint，实际为 int"},
+		{`//jni:native static com.demo.Main#name() String
+//export Java_com_demo_Main_name
+func f(env uintptr, clazz uintptr) string { return "" }`, "返回值的 Go 类型应为 uintptr、C.jobject、C.jstring，实际为 string"},
+		{`//jni:native static com.demo.Main#run() void
+//export Java_com_demo_Main_run
+func f(env uintptr, clazz uintptr) int32 { return 0 }`, "Java 方法没有返回值，Go 函数不能有返回值"},
+		{`//jni:native static com.demo.Main#size() int
+//export Java_com_demo_Main_size
+func f(env uintptr, clazz uintptr) {}`, "Go 函数需要一个返回值"},
+		{`//jni:native static com.demo.Main#get_value() int
+//export Java_com_demo_Main_get_value
+func f(env uintptr, clazz uintptr) int32 { return 0 }`, "导出名称应为 Java_com_demo_Main_get_1value"},
+		// 嵌套类的 $ 编码为 _00024
+		{`//jni:native static com.demo.Main#take(Outer.Inner v) void
+//export Java_com_demo_Main_take__Lcom_demo_Outer_Inner_2
+func f(env uintptr, clazz uintptr, v uintptr) {}`, "嵌套类名中的 $ 编码为 _00024"},
+		{`//jni:native static com.demo.Main#take(Outer.Inner v) void
+//export Java_com_demo_Main_take__Lcom_demo_Outer_00024Inner_2
+func f(env uintptr, clazz uintptr, v uintptr) {}`, ""},
+		// 重载方法必须使用长名称
+		{`//jni:native static com.demo.Main#hash(int n) long
+//export Java_com_demo_Main_hash
+func f(env uintptr, clazz uintptr, n int32) int64 { return 0 }
+
+//jni:native static com.demo.Main#hash(long n) long
+//export Java_com_demo_Main_hash__J
+func g(env uintptr, clazz uintptr, n int64) int64 { return 0 }`, "是重载方法，导出名称应为 Java_com_demo_Main_hash__I"},
+		{`//jni:native static com.demo.Main#hash(int n) long
+//export Java_com_demo_Main_hash__I
+func f(env uintptr, clazz uintptr, n int32) int64 { return 0 }
+
+//jni:native static com.demo.Main#hash(int m) long
+//export Java_com_demo_Main_hash__I
+func g(env uintptr, clazz uintptr, m int32) int64 { return 0 }`, "重复"},
+	}
+
+	for _, tt := range tests {
+		natives, errs := scanSource(t, tt.src)
+		errs = append(errs, check(natives)...)
+		switch {
+		case tt.want == "" && len(errs) > 0:
+			t.Errorf("%s\n不应该有错误: %v", tt.src, errs)
+		case tt.want != "" && (len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want)):
+			t.Errorf("%s\n错误为 %v，期望包含 %q", tt.src, errs, tt.want)
+		}
+	}
+}
+
+func TestWriteJava(t *testing.T) {
+	natives, errs := scan(filepath.Join("testdata", "native"))
+	errs = append(errs, check(natives)...)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	dir := t.TempDir()
+	if err := writeJava(dir, "demo", natives); err != nil {
+		t.Fatal(err)
+	}
+	for _, class := range []string{"com/demo/Main", "com/demo/Util"} {
+		path := filepath.FromSlash(class) + ".java"
+		got, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		golden := filepath.Join("testdata", "java", path)
+		if *update {
+			os.MkdirAll(filepath.Dir(golden), 0o755)
+			if err = os.WriteFile(golden, got, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("生成的代码与 %s 不同，使用 -update 更新:\n%s", golden, got)
+		}
+	}
+}
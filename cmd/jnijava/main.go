@@ -0,0 +1,52 @@
+// jnijava 扫描 Go 包中带有 //jni:native 指令的导出函数，生成对应的 Java 类：
+//
+//	//jni:native static com.demo.Main#hash(byte[] data) long
+//	//export Java_com_demo_Main_hash
+//	func Java_com_demo_Main_hash(env uintptr, clazz uintptr, data uintptr) int64 { ... }
+//
+// 导出函数中只能使用 uintptr、基本类型和 C 类型（如 C.jint、C.jstring），不能使用 jni.Jobject 等 Go 类型。
+// 执行 jnijava -lib hello -o src/java ./native 后生成 src/java/com/demo/Main.java，
+// 其中包含 native 方法声明和加载 libhello 的 static 代码块。
+//
+// 指令中的嵌套类写作 Outer.Inner 或 Outer$Inner，对应的描述符为 LOuter$Inner;。
+// 指令中省略 static 时为实例方法。jnijava 会检查 Go 函数的参数和返回值与 Java 类型是否匹配，
+// 并检查导出名称是否为 JNI 规范的符号名（重载方法需要使用带参数签名的长名称）。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var lib, output string
+	flag.StringVar(&lib, "lib", "", "System.loadLibrary 加载的库名称，如 hello")
+	flag.StringVar(&output, "o", ".", "Java 源代码的输出目录")
+	flag.Parse()
+
+	if lib == "" || flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "用法: jnijava -lib name [-o dir] <Go 包目录>...")
+		os.Exit(2)
+	}
+
+	var natives []*native
+	var errs []error
+	for _, dir := range flag.Args() {
+		list, err := scan(dir)
+		natives = append(natives, list...)
+		errs = append(errs, err...)
+	}
+	errs = append(errs, check(natives)...)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(1)
+	}
+
+	if err := writeJava(output, lib, natives); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 一个带有 //jni:native 指令的 Go 函数
+type native struct {
+	pos    token.Position
+	class  string // 内部类名，如 "com/demo/Main"
+	method string
+	static bool
+	params []param
+	ret    string // 返回值的字段描述符，void 为 "V"
+
+	export string // //export 的名称
+	fn     *ast.FuncDecl
+}
+
+type param struct {
+	name string
+	desc string
+	java string // Java 源代码中的类型
+}
+
+// 解析目录中的 Go 文件，返回找到的 native 函数和错误
+func scan(dir string) ([]*native, []error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	fset := token.NewFileSet()
+	var natives []*native
+	var errs []error
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Doc == nil || fd.Recv != nil {
+				continue
+			}
+			n, err := parseDirectives(fset, fd)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if n != nil {
+				natives = append(natives, n)
+			}
+		}
+	}
+	return natives, errs
+}
+
+func parseDirectives(fset *token.FileSet, fd *ast.FuncDecl) (*native, error) {
+	var n *native
+	var export string
+	for _, c := range fd.Doc.List {
+		if s, ok := strings.CutPrefix(c.Text, "//export "); ok {
+			export = strings.TrimSpace(s)
+		}
+		s, ok := strings.CutPrefix(c.Text, "//jni:native ")
+		if !ok {
+			continue
+		}
+		pos := fset.Position(c.Pos())
+		if n != nil {
+			return nil, fmt.Errorf("%s: 函数 %s 有多个 //jni:native 指令", pos, fd.Name.Name)
+		}
+		var err error
+		if n, err = parseDirective(strings.TrimSpace(s)); err != nil {
+			return nil, fmt.Errorf("%s: %w", pos, err)
+		}
+		n.pos = pos
+	}
+	if n == nil {
+		return nil, nil
+	}
+
+	if export == "" {
+		return nil, fmt.Errorf("%s: 函数 %s 缺少 //export 指令", n.pos, fd.Name.Name)
+	}
+	n.export, n.fn = export, fd
+	return n, nil
+}
+
+// 解析 "[static] com.demo.Main#hash(byte[] data, int) long"
+func parseDirective(s string) (*native, error) {
+	n := new(native)
+	if rest, ok := strings.CutPrefix(s, "static "); ok {
+		n.static, s = true, strings.TrimSpace(rest)
+	}
+
+	open, end := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	hash := strings.IndexByte(s, '#')
+	if hash < 0 || open < hash || end < open {
+		return nil, fmt.Errorf("无效的指令 %q，格式为 [static] com.demo.Main#method(int a, byte[] b) long", s)
+	}
+
+	className := strings.TrimSpace(s[:hash])
+	n.class = internalName(className)
+	if strings.Contains(n.class, "$") {
+		return nil, fmt.Errorf("不支持嵌套类 %s 的 native 方法，只能为顶层类生成 Java 代码", className)
+	}
+	n.method = strings.TrimSpace(s[hash+1 : open])
+	if !isJavaIdent(n.method) {
+		return nil, fmt.Errorf("无效的方法名 %q", n.method)
+	}
+	pkg := ""
+	if i := strings.LastIndexByte(n.class, '/'); i >= 0 {
+		pkg = n.class[:i+1]
+	}
+
+	if args := strings.TrimSpace(s[open+1 : end]); args != "" {
+		for i, arg := range strings.Split(args, ",") {
+			fields := strings.Fields(arg)
+			if len(fields) == 0 || len(fields) > 2 {
+				return nil, fmt.Errorf("无效的参数 %q", strings.TrimSpace(arg))
+			}
+			desc, err := descriptor(fields[0], pkg)
+			if err != nil {
+				return nil, err
+			}
+			if desc == "V" {
+				return nil, fmt.Errorf("参数 %q 的类型不能是 void", strings.TrimSpace(arg))
+			}
+			p := param{name: fmt.Sprintf("p%d", i), desc: desc, java: strings.ReplaceAll(fields[0], "$", ".")}
+			if len(fields) == 2 {
+				p.name = fields[1]
+			}
+			n.params = append(n.params, p)
+		}
+	}
+
+	ret := strings.TrimSpace(s[end+1:])
+	if ret == "" {
+		return nil, fmt.Errorf("缺少返回值类型（没有返回值时使用 void）")
+	}
+	var err error
+	if n.ret, err = descriptor(ret, pkg); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// java.lang 中常用的类可以省略包名，其他不带包名的类属于 native 方法所在的包
+var javaLang = map[string]bool{
+	"Object": true, "String": true, "Class": true, "Throwable": true, "CharSequence": true,
+	"Boolean": true, "Byte": true, "Character": true, "Short": true, "Integer": true,
+	"Long": true, "Float": true, "Double": true, "Number": true, "Runnable": true,
+}
+
+// 把 Java 源代码中的类型转换为字段描述符
+func descriptor(typ, pkg string) (string, error) {
+	dims := 0
+	for strings.HasSuffix(typ, "[]") {
+		typ = strings.TrimSpace(strings.TrimSuffix(typ, "[]"))
+		dims++
+	}
+
+	var desc string
+	switch typ {
+	case "void":
+		if dims > 0 {
+			return "", fmt.Errorf("无效的类型 void[]")
+		}
+		desc = "V"
+	case "boolean":
+		desc = "Z"
+	case "byte":
+		desc = "B"
+	case "char":
+		desc = "C"
+	case "short":
+		desc = "S"
+	case "int":
+		desc = "I"
+	case "long":
+		desc = "J"
+	case "float":
+		desc = "F"
+	case "double":
+		desc = "D"
+	default:
+		for _, part := range strings.Split(typ, ".") {
+			if !isJavaIdent(part) {
+				return "", fmt.Errorf("无效的类型 %q", typ)
+			}
+		}
+		name := internalName(typ)
+		outer, _, _ := strings.Cut(name, "$")
+		switch {
+		case strings.Contains(name, "/"):
+		case javaLang[outer]:
+			name = "java/lang/" + name
+		default:
+			name = pkg + name
+		}
+		desc = "L" + name + ";"
+	}
+	return strings.Repeat("[", dims) + desc, nil
+}
+
+// 把 Java 源代码中的类名转换为内部类名。按照 Java 的命名习惯，包名以小写字母开头，
+// 从第一个以大写字母开头的部分起是类名，之后的部分都是嵌套类，用 '$' 连接：
+// com.demo.Outer.Inner 转换为 com/demo/Outer$Inner。也可以直接写成 com.demo.Outer$Inner。
+func internalName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if r, _ := utf8.DecodeRuneInString(part); unicode.IsUpper(r) {
+			class := strings.Join(parts[i:], "$")
+			if i == 0 {
+				return class
+			}
+			return strings.Join(parts[:i], "/") + "/" + class
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func isJavaIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= 0x80 || i > 0 && r >= '0' && r <= '9' {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+// Code generated by jnijava. DO NOT EDIT.
+
+package com.demo;
+
+public class Main {
+    static {
+        System.loadLibrary("demo");
+    }
+
+    // 由 Go 函数 mainHash 实现
+    public static native long hash(byte[] data, int seed);
+
+    // 由 Go 函数 mainHashString 实现
+    public static native long hash(String s);
+
+    // 由 Go 函数 mainName 实现
+    public native java.lang.String name(Outer.Inner inner);
+
+    // 由 Go 函数 mainClear 实现
+    public native void clear(String[][] names, boolean all);
+}
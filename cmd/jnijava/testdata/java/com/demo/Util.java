@@ -0,0 +1,12 @@
+// Code generated by jnijava. DO NOT EDIT.
+
+package com.demo;
+
+public class Util {
+    static {
+        System.loadLibrary("demo");
+    }
+
+    // 由 Go 函数 utilVersion 实现
+    public static native java.util.List version();
+}
@@ -0,0 +1,25 @@
+package main
+
+import "C"
+
+//jni:native static com.demo.Main#hash(byte[] data, int seed) long
+//export Java_com_demo_Main_hash___3BI
+func mainHash(env uintptr, clazz uintptr, data C.jbyteArray, seed int32) int64 { return 0 }
+
+//jni:native static com.demo.Main#hash(String s) long
+//export Java_com_demo_Main_hash__Ljava_lang_String_2
+func mainHashString(env *C.JNIEnv, clazz C.jclass, s C.jstring) C.jlong { return 0 }
+
+//jni:native com.demo.Main#name(Outer.Inner inner) String
+//export Java_com_demo_Main_name
+func mainName(env uintptr, this C.jobject, inner uintptr) uintptr { return 0 }
+
+//jni:native com.demo.Main#clear(String[][] names, boolean all) void
+//export Java_com_demo_Main_clear
+func mainClear(env uintptr, this uintptr, names C.jobjectArray, all bool) {}
+
+//jni:native static com.demo.Util#version() java.util.List
+//export Java_com_demo_Util_version
+func utilVersion(env uintptr, clazz uintptr) uintptr { return 0 }
+
+func main() {}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ClarkGuan/jni/classfile"
+	"github.com/ClarkGuan/jni/mangle"
+)
+
+// 一个 native 方法
+type native struct {
+	class  string
+	name   string
+	desc   string
+	static bool
+	params []string
+	ret    string
+
+	symbol string // 导出函数名
+	impl   string // 手写的实现函数名
+}
+
+// 返回类中的 native 方法，描述符无效的方法不会被跳过，而是作为错误返回
+func nativesOf(c *classfile.Class) ([]*native, []error) {
+	count := make(map[string]int)
+	for _, m := range c.Methods {
+		if m.Is(classfile.AccNative) {
+			count[m.Name]++
+		}
+	}
+
+	var list []*native
+	var errs []error
+	for _, m := range c.Methods {
+		if !m.Is(classfile.AccNative) {
+			continue
+		}
+		params, ret, err := classfile.ParseMethodDescriptor(m.Descriptor)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s.%s: %w", c.Name, m.Name, err))
+			continue
+		}
+		symbol := mangle.ShortName(c.Name, m.Name)
+		if count[m.Name] > 1 {
+			if symbol, err = mangle.LongName(c.Name, m.Name, m.Descriptor); err != nil {
+				errs = append(errs, fmt.Errorf("%s.%s: %w", c.Name, m.Name, err))
+				continue
+			}
+		}
+		list = append(list, &native{
+			class:  c.Name,
+			name:   m.Name,
+			desc:   m.Descriptor,
+			static: m.Is(classfile.AccStatic),
+			params: params,
+			ret:    ret,
+			symbol: symbol,
+		})
+	}
+	return list, errs
+}
+
+// 实现函数名形如 mainHash，重名时加数字后缀
+func assignNames(natives []*native) {
+	used := make(map[string]int)
+	for _, n := range natives {
+		simple := n.class[strings.LastIndexByte(n.class, '/')+1:]
+		name := lowerFirst(ident(simple)) + upperFirst(ident(n.name))
+		if used[name]++; used[name] > 1 {
+			name += strconv.Itoa(used[name])
+		}
+		n.impl = name
+	}
+}
+
+func generateStubs(pkg string, natives []*native, register bool) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by jnistub. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+
+	fmt.Fprintf(&buf, "//\n// #include <jni.h>\n//\n")
+	if register {
+		// RegisterNatives 需要取导出函数的地址
+		for _, n := range natives {
+			fmt.Fprintf(&buf, "// extern %s %s(%s);\n", cType(n.ret), exportName(n, register), cParams(n))
+		}
+	}
+	fmt.Fprintf(&buf, "import \"C\"\nimport (\n")
+	if register {
+		fmt.Fprintf(&buf, "\"errors\"\n\"fmt\"\n")
+	}
+	fmt.Fprintf(&buf, "\"unsafe\"\n\n\"github.com/ClarkGuan/jni\"\n)\n\n")
+
+	for _, n := range natives {
+		stub(&buf, n, register)
+	}
+
+	if register {
+		registerTable(&buf, natives)
+	}
+
+	fmt.Fprintf(&buf, "func jniBool(b bool) C.jboolean {\nif b {\nreturn 1\n}\nreturn 0\n}\n")
+	return buf.Bytes()
+}
+
+func exportName(n *native, register bool) string {
+	if register {
+		// 不使用 Java_ 前缀，避免 JVM 按符号名自动绑定
+		return "jnistub_" + strings.TrimPrefix(n.symbol, "Java_")
+	}
+	return n.symbol
+}
+
+func stub(buf *bytes.Buffer, n *native, register bool) {
+	name := exportName(n, register)
+	fmt.Fprintf(buf, "// %s 对应 %s.%s%s\n", name, strings.ReplaceAll(n.class, "/", "."), n.name, n.desc)
+	fmt.Fprintf(buf, "//\n//export %s\n", name)
+
+	fmt.Fprintf(buf, "func %s(env *C.JNIEnv, self C.%s", name, selfType(n))
+	for i, p := range n.params {
+		fmt.Fprintf(buf, ", p%d C.%s", i, cType(p))
+	}
+	fmt.Fprintf(buf, ")")
+	if n.ret != "V" {
+		fmt.Fprintf(buf, " C.%s", cType(n.ret))
+	}
+	fmt.Fprintf(buf, " {\n")
+
+	args := []string{"jni.Env(unsafe.Pointer(env))", fmt.Sprintf("jni.%s(self)", upperFirst(selfType(n)))}
+	for i, p := range n.params {
+		args = append(args, goArg(p, fmt.Sprintf("p%d", i)))
+	}
+	call := fmt.Sprintf("%s(%s)", n.impl, strings.Join(args, ", "))
+
+	switch {
+	case n.ret == "V":
+		fmt.Fprintf(buf, "%s\n", call)
+	case n.ret == "Z":
+		fmt.Fprintf(buf, "return jniBool(%s)\n", call)
+	default:
+		fmt.Fprintf(buf, "return C.%s(%s)\n", cType(n.ret), call)
+	}
+	fmt.Fprintf(buf, "}\n\n")
+}
+
+func registerTable(buf *bytes.Buffer, natives []*native) {
+	fmt.Fprintf(buf, "// registerNatives 为所有类注册 native 方法，需要在 JNI_OnLoad 中调用\n")
+	fmt.Fprintf(buf, "func registerNatives(env jni.Env) error {\n")
+
+	var classes []string
+	byClass := make(map[string][]*native)
+	for _, n := range natives {
+		if _, ok := byClass[n.class]; !ok {
+			classes = append(classes, n.class)
+		}
+		byClass[n.class] = append(byClass[n.class], n)
+	}
+
+	fmt.Fprintf(buf, "var errs []error\n")
+	for _, class := range classes {
+		fmt.Fprintf(buf, "errs = append(errs, registerClass(env, %q, []jni.NativeMethod{\n", class)
+		for _, n := range byClass[class] {
+			fmt.Fprintf(buf, "{Name: %q, Signature: %q, FnPtr: unsafe.Pointer(C.%s)},\n", n.name, n.desc, exportName(n, true))
+		}
+		fmt.Fprintf(buf, "}))\n")
+	}
+	fmt.Fprintf(buf, "return errors.Join(errs...)\n}\n\n")
+
+	fmt.Fprintf(buf, `func registerClass(env jni.Env, name string, methods []jni.NativeMethod) error {
+	cls := env.FindClass(name)
+	if cls == 0 {
+		return fmt.Errorf("找不到类 %%s: %%v", name, env.CheckException())
+	}
+	defer env.DeleteLocalRef(cls)
+	if env.RegisterNatives(cls, methods) != jni.JNI_OK {
+		return fmt.Errorf("注册 %%s 的 native 方法失败: %%v", name, env.CheckException())
+	}
+	return nil
+}
+
+`)
+}
+
+func generateImpl(pkg string, natives []*native) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\nimport \"github.com/ClarkGuan/jni\"\n\n", pkg)
+	for _, n := range natives {
+		fmt.Fprintf(&buf, "// %s 实现 %s.%s%s\n", n.impl, strings.ReplaceAll(n.class, "/", "."), n.name, n.desc)
+		fmt.Fprintf(&buf, "func %s(env jni.Env, %s jni.%s", n.impl, selfName(n), upperFirst(selfType(n)))
+		for i, p := range n.params {
+			fmt.Fprintf(&buf, ", p%d %s", i, goType(p))
+		}
+		fmt.Fprintf(&buf, ")")
+		if n.ret != "V" {
+			fmt.Fprintf(&buf, " %s", goType(n.ret))
+		}
+		fmt.Fprintf(&buf, " {\n// TODO: 实现后删除下面的代码\njnistubUnsupported(env, %q)\n", strings.ReplaceAll(n.class, "/", ".")+"."+n.name)
+		if n.ret == "Z" {
+			fmt.Fprintf(&buf, "return false\n")
+		} else if n.ret != "V" {
+			fmt.Fprintf(&buf, "return 0\n")
+		}
+		fmt.Fprintf(&buf, "}\n\n")
+	}
+
+	// 这些函数直接在 JNI 调用中执行，panic 会导致整个 JVM 退出，所以抛出 Java 异常
+	fmt.Fprint(&buf, `// 未实现的方法在 Java 端抛出 UnsupportedOperationException
+func jnistubUnsupported(env jni.Env, method string) {
+	cls := env.FindClass("java/lang/UnsupportedOperationException")
+	if cls == 0 {
+		return
+	}
+	defer env.DeleteLocalRef(cls)
+	env.ThrowNew(cls, method+" 没有实现")
+}
+`)
+	return buf.Bytes()
+}
+
+func selfType(n *native) string {
+	if n.static {
+		return "jclass"
+	}
+	return "jobject"
+}
+
+func selfName(n *native) string {
+	if n.static {
+		return "clazz"
+	}
+	return "this"
+}
+
+func cParams(n *native) string {
+	params := []string{"JNIEnv *env", selfType(n) + " self"}
+	for i, p := range n.params {
+		params = append(params, fmt.Sprintf("%s p%d", cType(p), i))
+	}
+	return strings.Join(params, ", ")
+}
+
+// 字段描述符对应的 C 类型
+func cType(desc string) string {
+	switch desc {
+	case "V":
+		return "void"
+	case "Z":
+		return "jboolean"
+	case "B":
+		return "jbyte"
+	case "C":
+		return "jchar"
+	case "S":
+		return "jshort"
+	case "I":
+		return "jint"
+	case "J":
+		return "jlong"
+	case "F":
+		return "jfloat"
+	case "D":
+		return "jdouble"
+	case "Ljava/lang/String;":
+		return "jstring"
+	case "Ljava/lang/Class;":
+		return "jclass"
+	case "Ljava/lang/Throwable;":
+		return "jthrowable"
+	case "[Z":
+		return "jbooleanArray"
+	case "[B":
+		return "jbyteArray"
+	case "[C":
+		return "jcharArray"
+	case "[S":
+		return "jshortArray"
+	case "[I":
+		return "jintArray"
+	case "[J":
+		return "jlongArray"
+	case "[F":
+		return "jfloatArray"
+	case "[D":
+		return "jdoubleArray"
+	}
+	if desc[0] == '[' {
+		return "jobjectArray"
+	}
+	return "jobject"
+}
+
+// 字段描述符对应的 Go 类型，与 jni.Env 的函数一致
+func goType(desc string) string {
+	switch desc {
+	case "Z":
+		return "bool"
+	case "B":
+		return "byte"
+	case "C":
+		return "uint16"
+	case "S":
+		return "int16"
+	case "I":
+		return "int"
+	case "J":
+		return "int64"
+	case "F":
+		return "float32"
+	case "D":
+		return "float64"
+	}
+	return "jni." + upperFirst(cType(desc))
+}
+
+// 把 C 参数转换为 Go 参数
+func goArg(desc, name string) string {
+	if desc == "Z" {
+		return name + " != 0"
+	}
+	return goType(desc) + "(" + name + ")"
+}
+
+func ident(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '$' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
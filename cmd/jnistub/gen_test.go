@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ClarkGuan/jni/classfile"
+)
+
+var update = flag.Bool("update", false, "更新 testdata 中的 golden 文件")
+
+func demoNatives(t *testing.T) []*native {
+	t.Helper()
+	c, err := classfile.Load(filepath.Join("..", "..", "classfile", "testdata", "Demo.class"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	natives, errs := nativesOf(c[0])
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	assignNames(natives)
+	return natives
+}
+
+func TestGenerate(t *testing.T) {
+	natives := demoNatives(t)
+	tests := []struct {
+		golden string
+		code   []byte
+	}{
+		{"natives.go.golden", generateStubs("demo", natives, false)},
+		{"register.go.golden", generateStubs("demo", natives, true)},
+		{"impl.go.golden", generateImpl("demo", natives)},
+	}
+
+	for _, tt := range tests {
+		src, err := format.Source(tt.code)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.golden, err)
+		}
+		golden := filepath.Join("testdata", tt.golden)
+		if *update {
+			if err = os.WriteFile(golden, src, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(src) != string(want) {
+			t.Errorf("生成的代码与 %s 不同，使用 -update 更新:\n%s", golden, src)
+		}
+	}
+}
+
+func TestNativesOf(t *testing.T) {
+	natives := demoNatives(t)
+	tests := []struct {
+		symbol, impl string
+		static       bool
+	}{
+		// 重载的 hash 使用长名称
+		{"Java_com_demo_Demo_hash___3BI", "demoHash", false},
+		{"Java_com_demo_Demo_hash__Ljava_lang_String_2", "demoHash2", true},
+		{"Java_com_demo_Demo_reset", "demoReset", true},
+	}
+	if len(natives) != len(tests) {
+		t.Fatalf("找到 %d 个 native 方法，期望 %d 个", len(natives), len(tests))
+	}
+	for i, tt := range tests {
+		n := natives[i]
+		if n.symbol != tt.symbol || n.impl != tt.impl || n.static != tt.static {
+			t.Errorf("第 %d 个方法为 %s %s %t，期望 %s %s %t", i, n.symbol, n.impl, n.static, tt.symbol, tt.impl, tt.static)
+		}
+	}
+}
+
+func TestNativesOfError(t *testing.T) {
+	c := &classfile.Class{
+		Name: "com/demo/Bad",
+		Methods: []*classfile.Method{
+			{AccessFlags: classfile.AccNative, Name: "ok", Descriptor: "()V"},
+			{AccessFlags: classfile.AccNative, Name: "broken", Descriptor: "(Ljava/lang/String)V"},
+			{AccessFlags: classfile.AccNative, Name: "noReturn", Descriptor: "(I)"},
+			// 不是 native 方法，描述符不会被检查
+			{AccessFlags: classfile.AccPublic, Name: "other", Descriptor: "bad"},
+		},
+	}
+
+	natives, errs := nativesOf(c)
+	if len(natives) != 1 || natives[0].name != "ok" {
+		t.Errorf("找到 %d 个 native 方法，期望只有 ok", len(natives))
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "com/demo/Bad.broken") ||
+		!strings.Contains(errs[1].Error(), "com/demo/Bad.noReturn") {
+		t.Errorf("错误为 %v，期望报告 broken 和 noReturn", errs)
+	}
+}
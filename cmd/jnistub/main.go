@@ -0,0 +1,90 @@
+// jnistub 读取 .class/.jar 文件，为其中的 native 方法生成 cgo 导出函数：
+//
+//	jnistub -o natives.go -impl impl.go build/classes
+//
+// 生成的导出函数使用 JNI 规范的符号名（重载方法带有参数签名后缀），负责 C 类型与 Go 类型之间的转换，
+// 然后调用需要手写的实现函数，如 com.demo.Main 的 native long hash(byte[]) 对应：
+//
+//	func mainHash(env jni.Env, clazz jni.Jclass, p0 jni.JbyteArray) int64
+//
+// 使用 -register 时不使用规范的符号名，而是生成 registerNatives(env) 函数，在 JNI_OnLoad 中调用 RegisterNatives 注册。
+// -impl 指定的文件不存在时，会生成包含所有实现函数的骨架代码。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"strings"
+
+	"github.com/ClarkGuan/jni/classfile"
+)
+
+func main() {
+	var pkg, output, prefix, impl string
+	var register bool
+	flag.StringVar(&pkg, "p", "main", "指定 Go package 名称")
+	flag.StringVar(&output, "o", "", "输出文件，默认为标准输出")
+	flag.StringVar(&prefix, "c", "", "只处理类名以此为前缀的类，如 com/demo/")
+	flag.StringVar(&impl, "impl", "", "实现函数骨架的输出文件，文件已存在时不覆盖")
+	flag.BoolVar(&register, "register", false, "生成 RegisterNatives 注册表，而不是使用 JNI 规范的符号名")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "用法: jnistub [-p package] [-o output] [-c prefix] [-impl file] [-register] <.class|.jar|目录>...")
+		os.Exit(2)
+	}
+
+	classes, err := classfile.Load(flag.Args()...)
+	if err != nil {
+		fatal(err)
+	}
+
+	var natives []*native
+	var errs []error
+	for _, c := range classes {
+		if strings.HasPrefix(c.Name, prefix) {
+			list, err := nativesOf(c)
+			natives = append(natives, list...)
+			errs = append(errs, err...)
+		}
+	}
+	if len(errs) > 0 {
+		fatal(errors.Join(errs...))
+	}
+	if len(natives) == 0 {
+		fatal(fmt.Errorf("没有找到 native 方法"))
+	}
+	assignNames(natives)
+
+	if err = write(output, generateStubs(pkg, natives, register)); err != nil {
+		fatal(err)
+	}
+	if impl != "" {
+		if _, err := os.Stat(impl); err == nil {
+			return
+		}
+		if err = write(impl, generateImpl(pkg, natives)); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+func write(path string, code []byte) error {
+	src, err := format.Source(code)
+	if err != nil {
+		return fmt.Errorf("格式化生成的代码失败: %w", err)
+	}
+	if path == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(path, src, 0o644)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,199 @@
+package jni
+
+import "fmt"
+
+// Decoder 把 Java 对象转换为 Go 值。obj 是局部引用，调用结束后会被删除，Decoder 不应保存它。
+type Decoder[T any] func(env Env, obj Jobject) (T, error)
+
+// Encoder 把 Go 值转换为 Java 对象，返回的局部引用由调用者删除。
+type Encoder[T any] func(env Env, v T) (Jobject, error)
+
+// DecodeString 把 java.lang.String 转换为 Go 字符串，null 转换为 ""
+func DecodeString(env Env, obj Jobject) (string, error) {
+	return env.goString(obj), nil
+}
+
+// EncodeString 把 Go 字符串转换为 java.lang.String
+func EncodeString(env Env, s string) (Jobject, error) {
+	return env.javaString(s)
+}
+
+// DecodeStruct 返回通过 Unmarshal 转换元素的 Decoder
+func DecodeStruct[T any](opts ...UnmarshalOption) Decoder[T] {
+	return func(env Env, obj Jobject) (T, error) {
+		var v T
+		if obj == 0 {
+			return v, nil
+		}
+		err := Unmarshal(env, obj, &v, opts...)
+		return v, err
+	}
+}
+
+// EncodeStruct 返回通过 Marshal 把元素转换为 className 对象的 Encoder
+func EncodeStruct[T any](className string) Encoder[T] {
+	return func(env Env, v T) (Jobject, error) {
+		return Marshal(env, v, className)
+	}
+}
+
+// SliceOf 遍历 java.util.Collection（List、Set 等），用 decode 转换每个元素。
+// 每个元素的局部引用在转换后立即删除，遍历大集合也不会耗尽局部引用表。
+func SliceOf[T any](env Env, collection Jobject, decode Decoder[T]) ([]T, error) {
+	if collection == 0 {
+		return nil, nil
+	}
+
+	_, size, err := env.methodID("java/util/Collection", "size", "()I")
+	if err != nil {
+		return nil, err
+	}
+	n, err := env.callInt(collection, size)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]T, 0, n)
+	err = env.forEach(collection, func(elem Jobject) error {
+		v, err := decode(env, elem)
+		if err != nil {
+			return fmt.Errorf("jni: 第 %d 个元素: %w", len(ret), err)
+		}
+		ret = append(ret, v)
+		return nil
+	})
+	return ret, err
+}
+
+// MapOf 遍历 java.util.Map 的 entrySet()，用 decodeKey 和 decodeValue 转换每个键值对
+func MapOf[K comparable, V any](env Env, m Jobject, decodeKey Decoder[K], decodeValue Decoder[V]) (map[K]V, error) {
+	if m == 0 {
+		return nil, nil
+	}
+
+	_, entrySet, err := env.methodID("java/util/Map", "entrySet", "()Ljava/util/Set;")
+	if err != nil {
+		return nil, err
+	}
+	_, getKey, err := env.methodID("java/util/Map$Entry", "getKey", "()Ljava/lang/Object;")
+	if err != nil {
+		return nil, err
+	}
+	_, getValue, err := env.methodID("java/util/Map$Entry", "getValue", "()Ljava/lang/Object;")
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := env.callObject(m, entrySet)
+	if err != nil {
+		return nil, err
+	}
+	defer env.DeleteLocalRef(set)
+
+	ret := make(map[K]V)
+	err = env.forEach(set, func(entry Jobject) error {
+		k, err := decodeEntry(env, entry, getKey, decodeKey)
+		if err != nil {
+			return fmt.Errorf("jni: 键: %w", err)
+		}
+		v, err := decodeEntry(env, entry, getValue, decodeValue)
+		if err != nil {
+			return fmt.Errorf("jni: 键 %v 的值: %w", k, err)
+		}
+		ret[k] = v
+		return nil
+	})
+	return ret, err
+}
+
+func decodeEntry[T any](env Env, entry Jobject, getter JmethodID, decode Decoder[T]) (T, error) {
+	obj, err := env.callObject(entry, getter)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if obj != 0 {
+		defer env.DeleteLocalRef(obj)
+	}
+	return decode(env, obj)
+}
+
+// NewArrayList 创建 java.util.ArrayList，用 encode 转换 s 的每个元素
+func NewArrayList[T any](env Env, s []T, encode Encoder[T]) (Jobject, error) {
+	cls, ctor, err := env.methodID("java/util/ArrayList", "<init>", "(I)V")
+	if err != nil {
+		return 0, err
+	}
+	_, add, err := env.methodID("java/util/ArrayList", "add", "(Ljava/lang/Object;)Z")
+	if err != nil {
+		return 0, err
+	}
+
+	list, err := env.newObject(cls, ctor, ValueOf(len(s)))
+	if err != nil {
+		return 0, err
+	}
+	for i, v := range s {
+		elem, err := encode(env, v)
+		if err == nil {
+			_, err = env.callBoolean(list, add, ValueOf(elem))
+		}
+		if elem != 0 {
+			env.DeleteLocalRef(elem)
+		}
+		if err != nil {
+			env.DeleteLocalRef(list)
+			return 0, fmt.Errorf("jni: 第 %d 个元素: %w", i, err)
+		}
+	}
+	return list, nil
+}
+
+// NewHashMap 创建 java.util.HashMap，用 encodeKey 和 encodeValue 转换 m 的每个键值对
+func NewHashMap[K comparable, V any](env Env, m map[K]V, encodeKey Encoder[K], encodeValue Encoder[V]) (Jobject, error) {
+	cls, ctor, err := env.methodID("java/util/HashMap", "<init>", "(I)V")
+	if err != nil {
+		return 0, err
+	}
+	_, put, err := env.methodID("java/util/HashMap", "put", "(Ljava/lang/Object;Ljava/lang/Object;)Ljava/lang/Object;")
+	if err != nil {
+		return 0, err
+	}
+
+	// 与 HashMap(Map) 构造函数一样，按照默认负载因子 0.75 预留容量
+	hashMap, err := env.newObject(cls, ctor, ValueOf(len(m)*4/3+1))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range m {
+		if err = putEntry(env, hashMap, put, k, v, encodeKey, encodeValue); err != nil {
+			env.DeleteLocalRef(hashMap)
+			return 0, fmt.Errorf("jni: 键 %v: %w", k, err)
+		}
+	}
+	return hashMap, nil
+}
+
+func putEntry[K, V any](env Env, m Jobject, put JmethodID, k K, v V, encodeKey Encoder[K], encodeValue Encoder[V]) error {
+	key, err := encodeKey(env, k)
+	if err != nil {
+		return err
+	}
+	if key != 0 {
+		defer env.DeleteLocalRef(key)
+	}
+
+	val, err := encodeValue(env, v)
+	if err != nil {
+		return err
+	}
+	if val != 0 {
+		defer env.DeleteLocalRef(val)
+	}
+
+	old, err := env.callObject(m, put, ValueOf(key), ValueOf(val))
+	if old != 0 {
+		env.DeleteLocalRef(old)
+	}
+	return err
+}
@@ -0,0 +1,5 @@
+package jni
+
+func isReferenceDescriptor(desc string) bool {
+	return len(desc) > 0 && (desc[0] == 'L' || desc[0] == '[')
+}
@@ -24,6 +24,14 @@ package jni
 //     return (int) (*env)->GetObjectRefType(env, obj);
 // }
 //
+// static inline jclass DefineClass(JNIEnv * env, char * name, jobject loader, void * buf, jsize len) {
+//     return (*env)->DefineClass(env, name, loader, (const jbyte *) buf, len);
+// }
+//
+// static inline jint RegisterNatives(JNIEnv * env, jclass clazz, JNINativeMethod * methods, jint nMethods) {
+//     return (*env)->RegisterNatives(env, clazz, methods, nMethods);
+// }
+//
 // static inline jint DestroyJavaVM(JavaVM * vm) {
 //     return (*vm)->DestroyJavaVM(vm);
 // }
@@ -540,6 +548,10 @@ package jni
 //     (*env)->SetDoubleArrayRegion(env, array, start, len, buf);
 // }
 //
+// static inline jint UnregisterNatives(JNIEnv * env, jclass clazz) {
+//     return (*env)->UnregisterNatives(env, clazz);
+// }
+//
 // static inline jint MonitorEnter(JNIEnv * env, jobject obj) {
 //     return (*env)->MonitorEnter(env, obj);
 // }
@@ -584,6 +596,22 @@ package jni
 //     return (*env)->GetDirectBufferCapacity(env, buf);
 // }
 //
+// static inline jobject GetModule(JNIEnv * env, jclass clazz) {
+// #ifdef JNI_VERSION_9
+//     return (*env)->GetModule(env, clazz);
+// #else
+//     return 0;
+// #endif
+// }
+//
+// static inline jboolean IsVirtualThread(JNIEnv * env, jobject obj) {
+// #ifdef JNI_VERSION_21
+//     return (*env)->IsVirtualThread(env, obj);
+// #else
+//     return 0;
+// #endif
+// }
+//
 import "C"
 import (
 	"unicode/utf16"
@@ -598,6 +626,9 @@ const (
 	JNI_VERSION_1_8 = 0x00010008
 	JNI_VERSION_9   = 0x00090000
 	JNI_VERSION_10  = 0x000a0000
+	JNI_VERSION_19  = 0x00130000
+	JNI_VERSION_20  = 0x00140000
+	JNI_VERSION_21  = 0x00150000
 
 	JNI_FALSE = 0
 	JNI_TRUE  = 1
@@ -675,6 +706,37 @@ func (env Env) GetObjectRefType(obj Jobject) RefType {
 	return RefType(C.GetObjectRefType((*C.JNIEnv)(unsafe.Pointer(env)), C.jobject(obj)))
 }
 
+func (env Env) DefineClass(name string, loader Jobject, buf []byte) Jclass {
+	cstr_name := C.CString(name)
+	defer C.free(unsafe.Pointer(cstr_name))
+	return Jclass(C.DefineClass((*C.JNIEnv)(unsafe.Pointer(env)), cstr_name, C.jobject(loader), ByteSlicePtr(buf), C.jsize(len(buf))))
+}
+
+// NativeMethod 对应 C 的 JNINativeMethod，FnPtr 必须是 C 函数指针（如 cgo 导出的 Go 函数）
+type NativeMethod struct {
+	Name      string
+	Signature string
+	FnPtr     unsafe.Pointer
+}
+
+func (env Env) RegisterNatives(clazz Jclass, methods []NativeMethod) int {
+	size := C.size_t(unsafe.Sizeof(C.JNINativeMethod{})) * C.size_t(len(methods))
+	cmethods := (*C.JNINativeMethod)(C.malloc(size))
+	defer C.free(unsafe.Pointer(cmethods))
+
+	table := unsafe.Slice(cmethods, len(methods))
+	for i, m := range methods {
+		cstr_name := C.CString(m.Name)
+		defer C.free(unsafe.Pointer(cstr_name))
+		cstr_sig := C.CString(m.Signature)
+		defer C.free(unsafe.Pointer(cstr_sig))
+		table[i].name = cstr_name
+		table[i].signature = cstr_sig
+		table[i].fnPtr = m.FnPtr
+	}
+	return int(C.RegisterNatives((*C.JNIEnv)(unsafe.Pointer(env)), C.jclass(clazz), cmethods, C.jint(len(methods))))
+}
+
 func (env Env) NewString(s string) Jstring {
 	codes := utf16.Encode([]rune(s))
 	size := len(codes)
@@ -1323,6 +1385,10 @@ func (env Env) SetDoubleArrayRegion(array JdoubleArray, start int, buf []float64
 	C.SetDoubleArrayRegion((*C.JNIEnv)(unsafe.Pointer(env)), C.jdoubleArray(array), C.jsize(start), C.jsize(len(buf)), cDoubleArray(buf))
 }
 
+func (env Env) UnregisterNatives(clazz Jclass) int {
+	return int(C.UnregisterNatives((*C.JNIEnv)(unsafe.Pointer(env)), C.jclass(clazz)))
+}
+
 func (env Env) MonitorEnter(obj Jobject) int {
 	return int(C.MonitorEnter((*C.JNIEnv)(unsafe.Pointer(env)), C.jobject(obj)))
 }
@@ -1351,32 +1417,47 @@ func (env Env) ExceptionCheck() bool {
 	return C.ExceptionCheck((*C.JNIEnv)(unsafe.Pointer(env))) != C.JNI_FALSE
 }
 
+func (env Env) GetModule(clazz Jclass) (Jobject, error) {
+	if err := env.requireVersion(JNI_VERSION_9, "GetModule"); err != nil {
+		return 0, err
+	}
+	return Jobject(C.GetModule((*C.JNIEnv)(unsafe.Pointer(env)), C.jclass(clazz))), nil
+}
+
+func (env Env) IsVirtualThread(obj Jobject) (bool, error) {
+	if err := env.requireVersion(JNI_VERSION_21, "IsVirtualThread"); err != nil {
+		return false, err
+	}
+	return C.IsVirtualThread((*C.JNIEnv)(unsafe.Pointer(env)), C.jobject(obj)) != C.JNI_FALSE, nil
+}
+
 func DoubleValue(f float64) Jvalue {
-	return *(*Jvalue)(unsafe.Pointer(&f))
+	return ValueOf(f)
 }
 
 func FloatValue(f float32) Jvalue {
-	return Jvalue(*(*uint32)(unsafe.Pointer(&f)))
+	return ValueOf(f)
 }
 
 func Int8Value(i int8) Jvalue {
-	return Jvalue(*(*uint8)(unsafe.Pointer(&i)))
+	return ValueOf(i)
 }
 
 func Int16Value(i int16) Jvalue {
-	return Jvalue(*(*uint16)(unsafe.Pointer(&i)))
+	return ValueOf(i)
 }
 
 func Int32Value(i int32) Jvalue {
-	return Jvalue(*(*uint32)(unsafe.Pointer(&i)))
+	return ValueOf(i)
 }
 
+// IntValue 把 i 作为 jint 传递，在 32 位和 64 位平台上都只保留低 32 位
 func IntValue(i int) Jvalue {
-	return Jvalue(*(*uint)(unsafe.Pointer(&i)))
+	return ValueOf(i)
 }
 
 func BooleanValue(b bool) Jvalue {
-	return Jvalue(cbool(b))
+	return ValueOf(b)
 }
 
 func Bool(b uint8) bool {
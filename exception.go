@@ -0,0 +1,65 @@
+package jni
+
+import "errors"
+
+// Exception 表示 Java 代码抛出的异常
+type Exception struct {
+	ClassName string // 异常的类名，如 "java.io.IOException"
+	Message   string // getMessage() 的返回值
+}
+
+func (e *Exception) Error() string {
+	if e.Message == "" {
+		return e.ClassName
+	}
+	return e.ClassName + ": " + e.Message
+}
+
+// CheckException 检查当前线程是否有未处理的 Java 异常。
+// 如果有，清除该异常并以 *Exception 的形式返回；否则返回 nil。
+func (env Env) CheckException() error {
+	t := env.ExceptionOccurred()
+	if t == 0 {
+		return nil
+	}
+	env.ExceptionClear()
+	defer env.DeleteLocalRef(t)
+
+	return env.describeException(t)
+}
+
+// 读取异常的类名和消息，不使用缓存以免在查找类时再次递归进入 CheckException
+func (env Env) describeException(t Jthrowable) *Exception {
+	e := &Exception{ClassName: "java.lang.Throwable"}
+
+	cls := env.GetObjectClass(t)
+	defer env.DeleteLocalRef(cls)
+	classClass := env.GetObjectClass(cls)
+	defer env.DeleteLocalRef(classClass)
+
+	if getName := env.GetMethodID(classClass, "getName", "()Ljava/lang/String;"); getName != 0 {
+		if name := env.CallObjectMethodA(cls, getName); name != 0 {
+			e.ClassName = env.goString(name)
+			env.DeleteLocalRef(name)
+		}
+	}
+	env.ExceptionClear()
+
+	if getMessage := env.GetMethodID(cls, "getMessage", "()Ljava/lang/String;"); getMessage != 0 {
+		if msg := env.CallObjectMethodA(t, getMessage); msg != 0 {
+			e.Message = env.goString(msg)
+			env.DeleteLocalRef(msg)
+		}
+	}
+	env.ExceptionClear()
+
+	return e
+}
+
+// 如果有 Java 异常则返回该异常，否则返回描述为 msg 的错误
+func (env Env) exceptionOr(msg string) error {
+	if err := env.CheckException(); err != nil {
+		return err
+	}
+	return errors.New("jni: " + msg)
+}
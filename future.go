@@ -0,0 +1,272 @@
+package jni
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Await 等待 java.util.concurrent.CompletionStage（如 CompletableFuture）完成，返回结果的局部引用。
+//
+// Await 通过 whenComplete 注册回调，不会调用 get() 占用 JVM 线程。
+// future 异常完成时返回 *Exception（CompletionException 和 ExecutionException 会被展开）。
+// ctx 结束时取消 future（future 本身不是 Future 时取消 toCompletableFuture() 的结果）并返回 ctx.Err()。
+//
+// 和其他 Env 方法一样，Await 必须在 env 所属的线程中调用：native 方法中的 goroutine
+// 以及 vm.attach 的回调都已经锁定在该线程，其他 goroutine 需要先调用 runtime.LockOSThread。
+func Await(ctx context.Context, env Env, future Jobject) (Jobject, error) {
+	vm, err := env.vm()
+	if err != nil {
+		return 0, err
+	}
+	_, whenComplete, err := env.methodID("java/util/concurrent/CompletionStage", "whenComplete",
+		"(Ljava/util/function/BiConsumer;)Ljava/util/concurrent/CompletionStage;")
+	if err != nil {
+		return 0, err
+	}
+	_, cancel, err := env.methodID("java/util/concurrent/Future", "cancel", "(Z)Z")
+	if err != nil {
+		return 0, err
+	}
+	target, err := env.cancelTarget(future)
+	if err != nil {
+		return 0, err
+	}
+	if target != 0 {
+		defer env.DeleteGlobalRef(target)
+	}
+
+	type result struct {
+		obj Jobject // 全局引用
+		err error
+	}
+	var (
+		mu        sync.Mutex
+		abandoned bool
+		done      = make(chan result, 1)
+	)
+
+	// 回调可能在完成 future 的任意 Java 线程中执行；future 已经完成时在当前线程中立即执行
+	callback, err := Implement(env, "java/util/function/BiConsumer", functional("accept", func(env Env, call *ProxyCall) (Jobject, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if abandoned {
+			return 0, nil
+		}
+
+		var r result
+		if call.Args[1] != 0 {
+			r.err = env.throwableError(call.Args[1])
+		} else if call.Args[0] != 0 {
+			r.obj = env.NewGlobalRef(call.Args[0])
+		}
+		done <- r
+		return 0, nil
+	}))
+	if err != nil {
+		return 0, err
+	}
+	defer env.DeleteLocalRef(callback)
+
+	stage, err := env.callObject(future, whenComplete, ValueOf(callback))
+	if err != nil {
+		return 0, err
+	}
+	env.DeleteLocalRef(stage)
+
+	select {
+	case r := <-done:
+		return env.toLocalRef(r.obj), r.err
+
+	case <-ctx.Done():
+		if target != 0 {
+			vm.attach(func(env Env) error {
+				env.CallBooleanMethodA(target, cancel, ValueOf(true))
+				env.ExceptionClear()
+				return nil
+			})
+		}
+
+		// 已经产生的结果需要释放
+		mu.Lock()
+		abandoned = true
+		mu.Unlock()
+		select {
+		case r := <-done:
+			if r.obj != 0 {
+				env.DeleteGlobalRef(r.obj)
+			}
+		default:
+		}
+		return 0, ctx.Err()
+	}
+}
+
+// 返回取消 stage 时调用 cancel 的 Future 的全局引用。stage 不是 Future 时使用 toCompletableFuture()，
+// 它抛出 UnsupportedOperationException 时返回 0，表示无法取消。
+func (env Env) cancelTarget(stage Jobject) (Jobject, error) {
+	futureClass, err := env.findClass("java/util/concurrent/Future")
+	if err != nil {
+		return 0, err
+	}
+	if env.IsInstanceOf(stage, futureClass) {
+		return env.NewGlobalRef(stage), nil
+	}
+
+	_, toCompletableFuture, err := env.methodID("java/util/concurrent/CompletionStage", "toCompletableFuture",
+		"()Ljava/util/concurrent/CompletableFuture;")
+	if err != nil {
+		return 0, err
+	}
+	local, err := env.callObject(stage, toCompletableFuture)
+	var e *Exception
+	if errors.As(err, &e) && e.ClassName == "java.lang.UnsupportedOperationException" {
+		return 0, nil
+	} else if err != nil || local == 0 {
+		return 0, err
+	}
+	defer env.DeleteLocalRef(local)
+	return env.NewGlobalRef(local), nil
+}
+
+// NewFuture 创建一个 CompletableFuture（局部引用），并在新的 goroutine 中执行 fn，以其结果完成该 future。
+//
+// fn 的返回值可以是 nil、string、Box 支持的基本类型，或者 Jobject 全局引用（完成后由 NewFuture 删除）。
+// fn 返回错误时 future 以 RuntimeException 异常完成。
+// future 被 Java 端取消或以其他方式完成时，传给 fn 的 ctx 会被取消。
+func NewFuture(env Env, fn func(ctx context.Context) (any, error)) (Jobject, error) {
+	vm, err := env.vm()
+	if err != nil {
+		return 0, err
+	}
+	cls, ctor, err := env.methodID("java/util/concurrent/CompletableFuture", "<init>", "()V")
+	if err != nil {
+		return 0, err
+	}
+	_, whenComplete, err := env.methodID("java/util/concurrent/CompletableFuture", "whenComplete",
+		"(Ljava/util/function/BiConsumer;)Ljava/util/concurrent/CompletableFuture;")
+	if err != nil {
+		return 0, err
+	}
+
+	future, err := env.newObject(cls, ctor)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	callback, err := Implement(env, "java/util/function/BiConsumer", functional("accept", func(env Env, call *ProxyCall) (Jobject, error) {
+		cancel()
+		return 0, nil
+	}))
+	if err == nil {
+		var stage Jobject
+		if stage, err = env.callObject(future, whenComplete, ValueOf(callback)); err == nil {
+			env.DeleteLocalRef(stage)
+		}
+		env.DeleteLocalRef(callback)
+	}
+	if err != nil {
+		cancel()
+		env.DeleteLocalRef(future)
+		return 0, err
+	}
+
+	global := env.NewGlobalRef(future)
+	go func() {
+		defer cancel()
+		v, err := fn(ctx)
+		vm.attach(func(env Env) error {
+			defer env.DeleteGlobalRef(global)
+			env.completeFuture(global, v, err)
+			env.ExceptionClear()
+			return nil
+		})
+	}()
+	return future, nil
+}
+
+func (env Env) completeFuture(future Jobject, v any, err error) {
+	if err == nil {
+		var obj Jobject
+		if obj, err = env.futureValue(v); err == nil {
+			var complete JmethodID
+			_, complete, err = env.methodID("java/util/concurrent/CompletableFuture", "complete", "(Ljava/lang/Object;)Z")
+			if err == nil {
+				env.CallBooleanMethodA(future, complete, ValueOf(obj))
+			}
+			if obj != 0 {
+				env.DeleteLocalRef(obj)
+			}
+			if err == nil {
+				return
+			}
+		}
+	}
+
+	// 无论失败发生在哪一步都要结束 future，否则等待它的一方会一直阻塞
+
+	_, completeExceptionally, lookupErr := env.methodID("java/util/concurrent/CompletableFuture", "completeExceptionally",
+		"(Ljava/lang/Throwable;)Z")
+	if lookupErr != nil {
+		return
+	}
+	cls, ctor, lookupErr := env.methodID("java/lang/RuntimeException", "<init>", "(Ljava/lang/String;)V")
+	if lookupErr != nil {
+		return
+	}
+	msg, lookupErr := env.javaString(err.Error())
+	if lookupErr != nil {
+		return
+	}
+	defer env.DeleteLocalRef(msg)
+	t, lookupErr := env.newObject(cls, ctor, ValueOf(msg))
+	if lookupErr != nil {
+		return
+	}
+	defer env.DeleteLocalRef(t)
+	env.CallBooleanMethodA(future, completeExceptionally, ValueOf(t))
+}
+
+// 把 NewFuture 中 fn 的返回值转换为局部引用
+func (env Env) futureValue(v any) (Jobject, error) {
+	switch v := v.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		return env.javaString(v)
+	case Jobject:
+		defer env.DeleteGlobalRef(v)
+		return env.NewLocalRef(v), nil
+	}
+	return env.Box(v)
+}
+
+// 把 Throwable 转换为 *Exception，CompletionException 和 ExecutionException 使用其 cause
+func (env Env) throwableError(t Jobject) error {
+	_, getCause, err := env.methodID("java/lang/Throwable", "getCause", "()Ljava/lang/Throwable;")
+	if err != nil {
+		return err
+	}
+	for _, wrapper := range []string{"java/util/concurrent/CompletionException", "java/util/concurrent/ExecutionException"} {
+		cls, err := env.findClass(wrapper)
+		if err != nil || !env.IsInstanceOf(t, cls) {
+			continue
+		}
+		cause, err := env.callObject(t, getCause)
+		if err == nil && cause != 0 {
+			defer env.DeleteLocalRef(cause)
+			return env.describeException(cause)
+		}
+	}
+	return env.describeException(t)
+}
+
+// 把全局引用转换为局部引用并删除全局引用
+func (env Env) toLocalRef(global Jobject) Jobject {
+	if global == 0 {
+		return 0
+	}
+	defer env.DeleteGlobalRef(global)
+	return env.NewLocalRef(global)
+}
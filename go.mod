@@ -1,3 +1,3 @@
 module github.com/ClarkGuan/jni
 
-go 1.20
+go 1.23
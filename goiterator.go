@@ -0,0 +1,189 @@
+package jni
+
+//
+// #include <jni.h>
+//
+// extern jboolean goIteratorHasNext(JNIEnv *env, jobject self);
+// extern jobject goIteratorNext(JNIEnv *env, jobject self);
+// extern jobject goIterableIterator(JNIEnv *env, jobject self);
+import "C"
+import (
+	"iter"
+	"sync"
+	"unsafe"
+)
+
+// NewIterator 把 Go 的 iter.Seq 包装为 java.util.Iterator 对象（局部引用），Java 端按需拉取元素。
+// 每个元素在 Java 调用 next() 时通过 encode 转换，返回给 Java 的局部引用由 JVM 管理。
+//
+// seq 在单独的 goroutine 中执行；Java 对象被回收时会停止 seq 并释放 Go 端资源。
+// 对于只能遍历一次的数据源（如 channel）请使用 NewIterator，可以多次遍历的请使用 NewIterable。
+func NewIterator[T any](env Env, seq iter.Seq[T], encode Encoder[T]) (Jobject, error) {
+	return env.newPeer(iteratorPeer, newGoIterator(seq, encode))
+}
+
+// NewIterable 把 Go 的 iter.Seq 包装为 java.lang.Iterable 对象（局部引用），
+// 每次调用 iterator() 都会重新遍历 seq，因此可以用于 Java 的 for-each 和 Stream。
+func NewIterable[T any](env Env, seq iter.Seq[T], encode Encoder[T]) (Jobject, error) {
+	return env.newPeer(iterablePeer, &goIterable{
+		iterator: func() *goIterator {
+			return newGoIterator(seq, encode)
+		},
+	})
+}
+
+// ChanSeq 把 channel 转换为 iter.Seq，channel 关闭时结束
+func ChanSeq[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Java 的 hasNext()/next() 可能在不同线程中调用，所有操作都需要加锁
+type goIterator struct {
+	mu      sync.Mutex
+	hasNext func() bool
+	next    func(env Env) (Jobject, error)
+	stop    func()
+}
+
+// seq 在单独的 goroutine 中执行，每次请求产出一个元素。
+// 这里不能使用 iter.Pull：cgo 回调运行在锁定的线程上，iter.Pull 创建的协程要求之后的
+// 切换也在同一线程上，而 hasNext()/next() 和 Cleaner 的释放都可能来自其他 Java 线程。
+func newGoIterator[T any](seq iter.Seq[T], encode Encoder[T]) *goIterator {
+	req := make(chan struct{})
+	values := make(chan T)
+	done := make(chan struct{})
+	var panicked any
+
+	go func() {
+		defer close(values)
+		defer func() {
+			panicked = recover()
+		}()
+
+		select {
+		case <-req:
+		case <-done:
+			return
+		}
+		for v := range seq {
+			select {
+			case values <- v:
+			case <-done:
+				return
+			}
+			select {
+			case <-req:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// hasNext() 需要预先取出一个元素
+	var pending T
+	var peeked, ok bool
+	it := &goIterator{}
+	it.hasNext = func() bool {
+		if !peeked {
+			req <- struct{}{}
+			pending, ok = <-values
+			peeked = true
+			if !ok && panicked != nil {
+				// 在调用方的线程中重新抛出，由 recoverPanic 转换为 Java 异常
+				panic(panicked)
+			}
+		}
+		return ok
+	}
+	it.next = func(env Env) (Jobject, error) {
+		v := pending
+		var zero T
+		pending, peeked = zero, false
+		return encode(env, v)
+	}
+	it.stop = sync.OnceFunc(func() {
+		close(done)
+	})
+	return it
+}
+
+func (it *goIterator) release() {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	it.stop()
+}
+
+type goIterable struct {
+	iterator func() *goIterator
+}
+
+var iteratorPeer = &peerClass{
+	name:       "GoIterator",
+	super:      "java/lang/Object",
+	interfaces: []string{"java/util/Iterator"},
+	methods: []peerMethod{
+		{name: "hasNext", sig: "()Z", fn: unsafe.Pointer(C.goIteratorHasNext)},
+		{name: "next", sig: "()Ljava/lang/Object;", fn: unsafe.Pointer(C.goIteratorNext)},
+	},
+}
+
+var iterablePeer = &peerClass{
+	name:       "GoIterable",
+	super:      "java/lang/Object",
+	interfaces: []string{"java/lang/Iterable"},
+	methods: []peerMethod{
+		{name: "iterator", sig: "()Ljava/util/Iterator;", fn: unsafe.Pointer(C.goIterableIterator)},
+	},
+}
+
+//export goIteratorHasNext
+func goIteratorHasNext(cenv *C.JNIEnv, self C.jobject) C.jboolean {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	it := peerValue[*goIterator](env, iteratorPeer, Jobject(self))
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return cbool(it.hasNext())
+}
+
+//export goIteratorNext
+func goIteratorNext(cenv *C.JNIEnv, self C.jobject) C.jobject {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	it := peerValue[*goIterator](env, iteratorPeer, Jobject(self))
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if !it.hasNext() {
+		env.throwNew("java/util/NoSuchElementException", "")
+		return 0
+	}
+
+	obj, err := it.next(env)
+	if err != nil {
+		env.throwNew("java/lang/RuntimeException", err.Error())
+		return 0
+	}
+	return C.jobject(obj)
+}
+
+//export goIterableIterator
+func goIterableIterator(cenv *C.JNIEnv, self C.jobject) C.jobject {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	iterable := peerValue[*goIterable](env, iterablePeer, Jobject(self))
+	obj, err := env.newPeer(iteratorPeer, iterable.iterator())
+	if err != nil {
+		env.throwNew("java/lang/RuntimeException", err.Error())
+		return 0
+	}
+	return C.jobject(obj)
+}
@@ -0,0 +1,286 @@
+package jni
+
+//
+// #include <jni.h>
+//
+// extern jint goInputStreamRead(JNIEnv *env, jobject self);
+// extern jint goInputStreamReadArray(JNIEnv *env, jobject self, jbyteArray b, jint off, jint len);
+// extern void goInputStreamClose(JNIEnv *env, jobject self);
+// extern void goOutputStreamWrite(JNIEnv *env, jobject self, jint b);
+// extern void goOutputStreamWriteArray(JNIEnv *env, jobject self, jbyteArray b, jint off, jint len);
+// extern void goOutputStreamFlush(JNIEnv *env, jobject self);
+// extern void goOutputStreamClose(JNIEnv *env, jobject self);
+import "C"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"unsafe"
+)
+
+// NewInputStream 创建从 r 读取数据的 java.io.InputStream 对象（局部引用）。
+// Java 第一次调用 close() 时，如果 r 实现了 io.Closer 则调用其 Close 方法，之后的 close() 不做任何事情，
+// read() 抛出 IOException。
+// r 返回的错误（io.EOF 除外）在 Java 端抛出为 IOException。
+func NewInputStream(env Env, r io.Reader) (Jobject, error) {
+	return env.newPeer(inputStreamPeer, &goInputStream{r: r})
+}
+
+// NewOutputStream 创建向 w 写入数据的 java.io.OutputStream 对象（局部引用）。
+// Java 调用 flush() 时，如果 w 有 Flush() error 方法则调用它（如 bufio.Writer）；
+// 第一次调用 close() 时，如果 w 实现了 io.Closer 则调用其 Close 方法，之后的 close() 不做任何事情，
+// write() 抛出 IOException。
+// w 返回的错误在 Java 端抛出为 IOException。
+func NewOutputStream(env Env, w io.Writer) (Jobject, error) {
+	return env.newPeer(outputStreamPeer, &goOutputStream{w: w})
+}
+
+// 与 java.io 中关闭后读写抛出的异常信息一致
+const javaStreamClosed = "Stream closed"
+
+type goInputStream struct {
+	mu     sync.Mutex
+	r      io.Reader
+	buf    []byte
+	err    error // 已经读到但还没有报告给 Java 的错误
+	closed bool
+}
+
+// 读取至少一个字节，除非遇到错误
+func (s *goInputStream) read(n int) ([]byte, error) {
+	if s.closed {
+		return nil, errors.New(javaStreamClosed)
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(s.buf) < n {
+		s.buf = make([]byte, n)
+	}
+
+	for {
+		m, err := s.r.Read(s.buf[:n])
+		if m > 0 {
+			// 先返回已读到的数据，错误在下一次调用时报告
+			s.err = err
+			return s.buf[:m], nil
+		}
+		if err != nil {
+			s.err = err
+			return nil, err
+		}
+	}
+}
+
+// Closeable 要求重复调用 close() 没有任何效果，所以只关闭一次
+func (s *goInputStream) close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if c, ok := s.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+type goOutputStream struct {
+	mu     sync.Mutex
+	w      io.Writer
+	buf    []byte
+	closed bool
+}
+
+var inputStreamPeer = &peerClass{
+	name:  "GoInputStream",
+	super: "java/io/InputStream",
+	methods: []peerMethod{
+		{name: "read", sig: "()I", fn: unsafe.Pointer(C.goInputStreamRead)},
+		{name: "read", sig: "([BII)I", fn: unsafe.Pointer(C.goInputStreamReadArray)},
+		{name: "close", sig: "()V", fn: unsafe.Pointer(C.goInputStreamClose)},
+	},
+}
+
+var outputStreamPeer = &peerClass{
+	name:  "GoOutputStream",
+	super: "java/io/OutputStream",
+	methods: []peerMethod{
+		{name: "write", sig: "(I)V", fn: unsafe.Pointer(C.goOutputStreamWrite)},
+		{name: "write", sig: "([BII)V", fn: unsafe.Pointer(C.goOutputStreamWriteArray)},
+		{name: "flush", sig: "()V", fn: unsafe.Pointer(C.goOutputStreamFlush)},
+		{name: "close", sig: "()V", fn: unsafe.Pointer(C.goOutputStreamClose)},
+	},
+}
+
+//export goInputStreamRead
+func goInputStreamRead(cenv *C.JNIEnv, self C.jobject) C.jint {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	s := peerValue[*goInputStream](env, inputStreamPeer, Jobject(self))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.read(1)
+	if err == io.EOF {
+		return -1
+	}
+	if err != nil {
+		env.throwNew("java/io/IOException", err.Error())
+		return 0
+	}
+	return C.jint(b[0])
+}
+
+//export goInputStreamReadArray
+func goInputStreamReadArray(cenv *C.JNIEnv, self C.jobject, array C.jbyteArray, off, n C.jint) C.jint {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	if !env.checkRange(JbyteArray(array), int(off), int(n)) {
+		return 0
+	}
+	if n == 0 {
+		return 0
+	}
+
+	s := peerValue[*goInputStream](env, inputStreamPeer, Jobject(self))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.read(min(int(n), streamBufferSize))
+	if err == io.EOF {
+		return -1
+	}
+	if err != nil {
+		env.throwNew("java/io/IOException", err.Error())
+		return 0
+	}
+	env.SetByteArrayRegion(JbyteArray(array), int(off), b)
+	return C.jint(len(b))
+}
+
+//export goInputStreamClose
+func goInputStreamClose(cenv *C.JNIEnv, self C.jobject) {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	s := peerValue[*goInputStream](env, inputStreamPeer, Jobject(self))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.close(); err != nil {
+		env.throwNew("java/io/IOException", err.Error())
+	}
+}
+
+//export goOutputStreamWrite
+func goOutputStreamWrite(cenv *C.JNIEnv, self C.jobject, b C.jint) {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	s := peerValue[*goOutputStream](env, outputStreamPeer, Jobject(self))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.write([]byte{byte(b)}); err != nil {
+		env.throwNew("java/io/IOException", err.Error())
+	}
+}
+
+//export goOutputStreamWriteArray
+func goOutputStreamWriteArray(cenv *C.JNIEnv, self C.jobject, array C.jbyteArray, off, n C.jint) {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	if !env.checkRange(JbyteArray(array), int(off), int(n)) {
+		return
+	}
+
+	s := peerValue[*goOutputStream](env, outputStreamPeer, Jobject(self))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		env.throwNew("java/io/IOException", javaStreamClosed)
+		return
+	}
+
+	if len(s.buf) < streamBufferSize {
+		s.buf = make([]byte, streamBufferSize)
+	}
+	for start, end := int(off), int(off+n); start < end; {
+		chunk := s.buf[:min(end-start, streamBufferSize)]
+		env.GetByteArrayRegion(JbyteArray(array), start, chunk)
+		if err := s.write(chunk); err != nil {
+			env.throwNew("java/io/IOException", err.Error())
+			return
+		}
+		start += len(chunk)
+	}
+}
+
+func (s *goOutputStream) write(p []byte) error {
+	if s.closed {
+		return errors.New(javaStreamClosed)
+	}
+	n, err := s.w.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	return err
+}
+
+//export goOutputStreamFlush
+func goOutputStreamFlush(cenv *C.JNIEnv, self C.jobject) {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	s := peerValue[*goOutputStream](env, outputStreamPeer, Jobject(self))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 包装流在关闭时可能还会调用 flush()，关闭之后忽略
+	if s.closed {
+		return
+	}
+	if f, ok := s.w.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			env.throwNew("java/io/IOException", err.Error())
+		}
+	}
+}
+
+//export goOutputStreamClose
+func goOutputStreamClose(cenv *C.JNIEnv, self C.jobject) {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	s := peerValue[*goOutputStream](env, outputStreamPeer, Jobject(self))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.close(); err != nil {
+		env.throwNew("java/io/IOException", err.Error())
+	}
+}
+
+func (s *goOutputStream) close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// 按照 InputStream/OutputStream 的约定检查参数，不合法时抛出异常并返回 false
+func (env Env) checkRange(array JbyteArray, off, n int) bool {
+	if array == 0 {
+		env.throwNew("java/lang/NullPointerException", "")
+		return false
+	}
+	if length := env.GetArrayLength(array); off < 0 || n < 0 || n > length-off {
+		env.throwNew("java/lang/IndexOutOfBoundsException", fmt.Sprintf("off=%d, len=%d, length=%d", off, n, length))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package jni
+
+import "iter"
+
+// Iterate 返回遍历 java.lang.Iterable 或 java.util.Iterator 的迭代器，以及读取遍历错误的函数：
+//
+//	seq, errOf := jni.Iterate(env, list)
+//	for obj := range seq {
+//		// obj 只在本次循环内有效
+//	}
+//	if err := errOf(); err != nil {
+//		// hasNext()/next() 抛出了异常
+//	}
+//
+// 每个元素都是局部引用，进入下一次循环（或退出循环）时被删除，
+// 需要保留时请使用 NewLocalRef 或 NewGlobalRef。
+// hasNext() 或 next() 抛出的 Java 异常会被清除并结束遍历，之后由 errOf 返回。
+func Iterate(env Env, iterable Jobject) (seq iter.Seq[Jobject], errOf func() error) {
+	var err error
+	seq = func(yield func(Jobject) bool) {
+		err = env.iterate(iterable, func(elem Jobject) (bool, error) {
+			return yield(elem), nil
+		})
+	}
+	return seq, func() error { return err }
+}
+
+// IterateArray 返回遍历 Java 对象数组的迭代器，产生下标和元素。
+// 与 Iterate 一样，每个元素的局部引用在进入下一次循环时被删除。
+func IterateArray(env Env, array JobjectArray) iter.Seq2[int, Jobject] {
+	return func(yield func(int, Jobject) bool) {
+		if array == 0 {
+			return
+		}
+
+		n := env.GetArrayLength(array)
+		for i := 0; i < n; i++ {
+			elem := env.GetObjectArrayElement(array, i)
+			more := yield(i, elem)
+			if elem != 0 {
+				env.DeleteLocalRef(elem)
+			}
+			if !more {
+				return
+			}
+		}
+	}
+}
+
+// 与 iterate 相同，但 fn 返回错误时结束遍历
+func (env Env) forEach(iterable Jobject, fn func(elem Jobject) error) error {
+	return env.iterate(iterable, func(elem Jobject) (bool, error) {
+		err := fn(elem)
+		return err == nil, err
+	})
+}
+
+// 遍历 Iterable 或 Iterator，每个元素在 fn 返回后立即删除局部引用。
+// fn 返回 false 或错误时结束遍历。
+func (env Env) iterate(obj Jobject, fn func(elem Jobject) (bool, error)) error {
+	if obj == 0 {
+		return nil
+	}
+
+	iteratorClass, hasNext, err := env.methodID("java/util/Iterator", "hasNext", "()Z")
+	if err != nil {
+		return err
+	}
+	_, next, err := env.methodID("java/util/Iterator", "next", "()Ljava/lang/Object;")
+	if err != nil {
+		return err
+	}
+
+	it := obj
+	if !env.IsInstanceOf(obj, iteratorClass) {
+		_, iterator, err := env.methodID("java/lang/Iterable", "iterator", "()Ljava/util/Iterator;")
+		if err != nil {
+			return err
+		}
+		if it, err = env.callObject(obj, iterator); err != nil {
+			return err
+		}
+		defer env.DeleteLocalRef(it)
+	}
+
+	for {
+		more, err := env.callBoolean(it, hasNext)
+		if err != nil || !more {
+			return err
+		}
+		elem, err := env.callObject(it, next)
+		if err != nil {
+			return err
+		}
+		more, err = fn(elem)
+		if elem != 0 {
+			env.DeleteLocalRef(elem)
+		}
+		if err != nil || !more {
+			return err
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package mangle 实现 JNI 规范中 native 方法符号名的编码和解码：
+//
+//	Java_<类名>_<方法名>            短名称
+//	Java_<类名>_<方法名>__<参数>    重载方法使用的长名称
+//
+// 类名中的 '/' 编码为 '_'，其他字符的转义规则为：'_' -> "_1"，';' -> "_2"，'[' -> "_3"，
+// 非字母数字的字符按 UTF-16 编码为 "_0xxxx"（小写十六进制）。
+package mangle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Name 是 native 方法符号名对应的 Java 方法
+type Name struct {
+	Class  string // 内部类名，如 "com/demo/Main"
+	Method string
+
+	// 参数描述符（不包括括号和返回值），如 "[BI"；只有长名称才有
+	Params     string
+	Overloaded bool
+}
+
+// Descriptor 返回不包括返回值的方法描述符，如 "([BI)"；短名称返回空字符串
+func (n Name) Descriptor() string {
+	if !n.Overloaded {
+		return ""
+	}
+	return "(" + n.Params + ")"
+}
+
+func (n Name) String() string {
+	s := strings.ReplaceAll(n.Class, "/", ".") + "." + n.Method
+	if n.Overloaded {
+		s += n.Descriptor()
+	}
+	return s
+}
+
+// Symbol 返回 n 的符号名
+func (n Name) Symbol() string {
+	s := "Java_" + Mangle(n.Class) + "_" + Mangle(n.Method)
+	if n.Overloaded {
+		s += "__" + Mangle(n.Params)
+	}
+	return s
+}
+
+// ShortName 返回方法的短名称，如 ShortName("com/demo/Main", "hash") == "Java_com_demo_Main_hash"
+func ShortName(class, method string) string {
+	return Name{Class: class, Method: method}.Symbol()
+}
+
+// LongName 返回重载方法使用的长名称，descriptor 是完整的方法描述符，如 "([BI)J"
+func LongName(class, method, descriptor string) (string, error) {
+	end := strings.IndexByte(descriptor, ')')
+	if !strings.HasPrefix(descriptor, "(") || end < 0 {
+		return "", fmt.Errorf("mangle: 无效的方法描述符 %q", descriptor)
+	}
+	return Name{Class: class, Method: method, Params: descriptor[1:end], Overloaded: true}.Symbol(), nil
+}
+
+// Mangle 按照 JNI 规范转义 s，'/' 转换为 '_'
+func Mangle(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '/':
+			sb.WriteByte('_')
+		case r == '_':
+			sb.WriteString("_1")
+		case r == ';':
+			sb.WriteString("_2")
+		case r == '[':
+			sb.WriteString("_3")
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		default:
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&sb, "_0%04x", u)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// Unmangle 是 Mangle 的逆操作，没有转义的 '_' 转换为 '/'
+func Unmangle(s string) (string, error) {
+	var codes []uint16
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '_' {
+			codes = append(codes, uint16(c))
+			continue
+		}
+
+		if i+1 == len(s) {
+			codes = append(codes, '/')
+			continue
+		}
+		switch s[i+1] {
+		case '1':
+			codes = append(codes, '_')
+			i++
+		case '2':
+			codes = append(codes, ';')
+			i++
+		case '3':
+			codes = append(codes, '[')
+			i++
+		case '0':
+			if i+6 > len(s) {
+				return "", fmt.Errorf("mangle: %q 中第 %d 个字符处的 _0 转义不完整", s, i)
+			}
+			u, err := strconv.ParseUint(s[i+2:i+6], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("mangle: %q 中第 %d 个字符处的 _0 转义无效", s, i)
+			}
+			codes = append(codes, uint16(u))
+			i += 5
+		default:
+			codes = append(codes, '/')
+		}
+	}
+	return string(utf16.Decode(codes)), nil
+}
+
+// Demangle 解析 native 方法的符号名，如 "Java_com_demo_Main_hash___3BI" 对应 com/demo/Main 的 hash，参数为 "[BI"
+func Demangle(symbol string) (Name, error) {
+	rest, ok := strings.CutPrefix(symbol, "Java_")
+	if !ok {
+		return Name{}, fmt.Errorf("mangle: %q 不是以 Java_ 开头", symbol)
+	}
+
+	var n Name
+	if i := signatureSeparator(rest); i >= 0 {
+		params, err := Unmangle(rest[i+2:])
+		if err != nil {
+			return Name{}, err
+		}
+		n.Params, n.Overloaded = params, true
+		rest = rest[:i]
+	}
+
+	// 方法名中不会有 '/'，最后一个未转义的 '_' 分隔类名和方法名
+	i := lastSeparator(rest)
+	if i <= 0 || i == len(rest)-1 {
+		return Name{}, fmt.Errorf("mangle: %q 中缺少类名或方法名", symbol)
+	}
+	var err error
+	if n.Class, err = Unmangle(rest[:i]); err != nil {
+		return Name{}, err
+	}
+	if n.Method, err = Unmangle(rest[i+1:]); err != nil {
+		return Name{}, err
+	}
+	return n, nil
+}
+
+// 返回参数签名分隔符 "__" 的位置。转义序列总是 '_' 加数字，因此未转义的 "__" 只能是分隔符，
+// 或者方法名以转义字符开头（如 "__0540d"）；参数签名只能以字母或 "_3"（'['）开头，据此区分两者。
+func signatureSeparator(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '_' {
+			continue
+		}
+		if strings.HasPrefix(s[i:], "__") && (i+2 == len(s) || s[i+2] < '0' || s[i+2] > '2') {
+			return i
+		}
+		if isEscape(s, i) {
+			i++
+		}
+	}
+	return -1
+}
+
+// 返回最后一个未转义的 '_' 的位置
+func lastSeparator(s string) int {
+	last := -1
+	for i := 0; i < len(s); i++ {
+		if s[i] != '_' {
+			continue
+		}
+		if isEscape(s, i) {
+			i++
+		} else {
+			last = i
+		}
+	}
+	return last
+}
+
+func isEscape(s string, i int) bool {
+	return i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '3'
+}
@@ -0,0 +1,117 @@
+package mangle
+
+import "testing"
+
+var names = []struct {
+	name   Name
+	symbol string
+}{
+	{Name{Class: "com/demo/Main", Method: "hash"}, "Java_com_demo_Main_hash"},
+	{Name{Class: "Main", Method: "run"}, "Java_Main_run"},
+	{Name{Class: "com/my_app/Main", Method: "get_value"}, "Java_com_my_1app_Main_get_1value"},
+	{Name{Class: "com/demo/Outer$Inner", Method: "run"}, "Java_com_demo_Outer_00024Inner_run"},
+	{Name{Class: "com/demo/Main", Method: "中文"}, "Java_com_demo_Main__04e2d_06587"},
+	{Name{Class: "com/demo/Main", Method: "smile\U0001F600"}, "Java_com_demo_Main_smile_0d83d_0de00"},
+	{Name{Class: "com/demo/Main", Method: "hash", Params: "[BI", Overloaded: true}, "Java_com_demo_Main_hash___3BI"},
+	{Name{Class: "com/demo/Main", Method: "hash", Overloaded: true}, "Java_com_demo_Main_hash__"},
+	{Name{Class: "com/demo/Main", Method: "put", Params: "Ljava/lang/String;J", Overloaded: true}, "Java_com_demo_Main_put__Ljava_lang_String_2J"},
+	{Name{Class: "com/demo/Main", Method: "put", Params: "[[Ljava/lang/String;", Overloaded: true}, "Java_com_demo_Main_put___3_3Ljava_lang_String_2"},
+	// 方法名以转义字符开头时，"__0" 不是参数签名分隔符
+	{Name{Class: "com/demo/Main", Method: "中", Params: "I", Overloaded: true}, "Java_com_demo_Main__04e2d__I"},
+	{Name{Class: "com/demo/Main", Method: "_init", Params: "I", Overloaded: true}, "Java_com_demo_Main__1init__I"},
+	{Name{Class: "com/demo/Outer$Inner", Method: "a_b", Params: "Lcom/demo/Outer$Inner;", Overloaded: true}, "Java_com_demo_Outer_00024Inner_a_1b__Lcom_demo_Outer_00024Inner_2"},
+}
+
+func TestSymbol(t *testing.T) {
+	for _, tt := range names {
+		if got := tt.name.Symbol(); got != tt.symbol {
+			t.Errorf("%v.Symbol() = %q, want %q", tt.name, got, tt.symbol)
+		}
+	}
+}
+
+func TestDemangle(t *testing.T) {
+	for _, tt := range names {
+		got, err := Demangle(tt.symbol)
+		if err != nil {
+			t.Errorf("Demangle(%q): %v", tt.symbol, err)
+			continue
+		}
+		if got != tt.name {
+			t.Errorf("Demangle(%q) = %#v, want %#v", tt.symbol, got, tt.name)
+		}
+	}
+}
+
+func TestShortName(t *testing.T) {
+	if got, want := ShortName("com/demo/Main", "hash"), "Java_com_demo_Main_hash"; got != want {
+		t.Errorf("ShortName = %q, want %q", got, want)
+	}
+}
+
+func TestLongName(t *testing.T) {
+	tests := []struct {
+		descriptor string
+		want       string
+	}{
+		{"([BI)J", "Java_com_demo_Main_hash___3BI"},
+		{"()V", "Java_com_demo_Main_hash__"},
+		{"(Ljava/lang/String;)V", "Java_com_demo_Main_hash__Ljava_lang_String_2"},
+	}
+	for _, tt := range tests {
+		got, err := LongName("com/demo/Main", "hash", tt.descriptor)
+		if err != nil {
+			t.Errorf("LongName(%q): %v", tt.descriptor, err)
+		} else if got != tt.want {
+			t.Errorf("LongName(%q) = %q, want %q", tt.descriptor, got, tt.want)
+		}
+	}
+
+	for _, descriptor := range []string{"", "[BI)J", "([BI"} {
+		if _, err := LongName("com/demo/Main", "hash", descriptor); err == nil {
+			t.Errorf("LongName(%q) 没有返回错误", descriptor)
+		}
+	}
+}
+
+func TestMangle(t *testing.T) {
+	tests := []struct {
+		s, mangled string
+	}{
+		{"abc123", "abc123"},
+		{"a/b", "a_b"},
+		{"a_b", "a_1b"},
+		{"La;", "La_2"},
+		{"[I", "_3I"},
+		{"$", "_00024"},
+		{"é", "_000e9"},
+		{"\U0001F600", "_0d83d_0de00"},
+	}
+	for _, tt := range tests {
+		if got := Mangle(tt.s); got != tt.mangled {
+			t.Errorf("Mangle(%q) = %q, want %q", tt.s, got, tt.mangled)
+		}
+		got, err := Unmangle(tt.mangled)
+		if err != nil {
+			t.Errorf("Unmangle(%q): %v", tt.mangled, err)
+		} else if got != tt.s {
+			t.Errorf("Unmangle(%q) = %q, want %q", tt.mangled, got, tt.s)
+		}
+	}
+}
+
+func TestDemangleError(t *testing.T) {
+	for _, symbol := range []string{
+		"com_demo_Main_hash",
+		"Java_Main",
+		"Java__hash",
+		"Java_Main_",
+		"Java_Main_a_0zz",
+		"Java_Main_a_00",
+		"Java_Main_a__I_0",
+	} {
+		if n, err := Demangle(symbol); err == nil {
+			t.Errorf("Demangle(%q) = %#v，没有返回错误", symbol, n)
+		}
+	}
+}
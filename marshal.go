@@ -0,0 +1,227 @@
+package jni
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Marshal 创建一个 className（如 "com/demo/User"）的 Java 对象，
+// 并按照 `jni:"fieldName"` 标签把结构体 v 的字段写入该对象的同名字段。
+//
+// 对象通过无参构造函数创建，没有无参构造函数时使用 AllocObject。
+// 支持的字段类型：
+//
+//	bool、整数、浮点数 -> 对应的 Java 基本类型（int 表示 jint）
+//	string             -> java.lang.String
+//	[]基本类型、[]string -> Java 数组
+//	嵌套结构体         -> 标签中指定的 Java 类型，如 `jni:"address,Lcom/demo/Address;"`
+//	*基本类型          -> 包装类，如 *int32 -> java.lang.Integer
+//	Jobject            -> 原样写入
+//
+// nil 指针和 nil 切片写入 null。返回的对象是局部引用，v 为 nil 指针时返回 0。
+func Marshal(env Env, v any, className string) (Jobject, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return 0, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return 0, fmt.Errorf("jni: Marshal 需要结构体，实际为 %T", v)
+	}
+
+	return env.marshalStruct(rv, className)
+}
+
+func (env Env) marshalStruct(v reflect.Value, className string) (Jobject, error) {
+	info, err := env.structInfoOf(v.Type(), className, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var obj Jobject
+	if info.ctor != 0 {
+		obj = env.NewObjectA(info.class, info.ctor)
+	} else {
+		obj = env.AllocObject(info.class)
+	}
+	if obj == 0 {
+		return 0, env.exceptionOr("创建 " + className + " 对象失败")
+	}
+
+	for _, f := range info.fields {
+		if err = env.setField(obj, f, v.Field(f.index)); err != nil {
+			env.DeleteLocalRef(obj)
+			return 0, fmt.Errorf("jni: 写入 %s.%s: %w", className, f.name, err)
+		}
+	}
+	return obj, nil
+}
+
+func (env Env) setField(obj Jobject, f structField, v reflect.Value) error {
+	switch f.sig[0] {
+	case 'Z':
+		env.SetBooleanField(obj, f.id, v.Bool())
+	case 'B':
+		env.SetByteField(obj, f.id, byte(intOf(v)))
+	case 'C':
+		env.SetCharField(obj, f.id, uint16(intOf(v)))
+	case 'S':
+		env.SetShortField(obj, f.id, int16(intOf(v)))
+	case 'I':
+		env.SetIntField(obj, f.id, int(int32(intOf(v))))
+	case 'J':
+		env.SetLongField(obj, f.id, intOf(v))
+	case 'F':
+		env.SetFloatField(obj, f.id, float32(v.Float()))
+	case 'D':
+		env.SetDoubleField(obj, f.id, v.Float())
+	default:
+		ref, err := env.toJava(v, f.sig)
+		if err != nil {
+			return err
+		}
+		env.SetObjectField(obj, f.id, ref)
+		if ref != 0 {
+			env.DeleteLocalRef(ref)
+		}
+	}
+	return nil
+}
+
+// 把 Go 值转换为描述符为 sig 的 Java 引用，返回局部引用
+func (env Env) toJava(v reflect.Value, sig string) (Jobject, error) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return 0, nil
+		}
+		if p, ok := primitiveKinds[v.Elem().Kind()]; ok {
+			return env.box(p, primitiveValue(v.Elem(), p))
+		}
+		return env.toJava(v.Elem(), sig)
+
+	case reflect.String:
+		return env.javaString(v.String())
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return 0, nil
+		}
+		return env.newArray(v, sig[1:])
+
+	case reflect.Struct:
+		return env.marshalStruct(v, classOfDescriptor(sig))
+
+	case reflect.Uintptr:
+		if v.Uint() == 0 {
+			return 0, nil
+		}
+		return env.NewLocalRef(Jobject(v.Uint())), nil
+	}
+
+	return 0, fmt.Errorf("不支持的类型 %s", v.Type())
+}
+
+// 创建元素描述符为 elem 的 Java 数组，并把切片 v 的内容复制进去
+func (env Env) newArray(v reflect.Value, elem string) (Jarray, error) {
+	n := v.Len()
+	var arr Jarray
+	switch elem[0] {
+	case 'Z':
+		if arr = env.NewBooleanArray(n); arr != 0 {
+			env.SetBooleanArrayRegion(arr, 0, sliceOf(v, reflect.Value.Bool))
+		}
+	case 'B':
+		if arr = env.NewByteArray(n); arr != 0 {
+			env.SetByteArrayRegion(arr, 0, sliceOf(v, func(e reflect.Value) byte { return byte(intOf(e)) }))
+		}
+	case 'C':
+		if arr = env.NewCharArray(n); arr != 0 {
+			env.SetCharArrayRegion(arr, 0, sliceOf(v, func(e reflect.Value) uint16 { return uint16(intOf(e)) }))
+		}
+	case 'S':
+		if arr = env.NewShortArray(n); arr != 0 {
+			env.SetShortArrayRegion(arr, 0, sliceOf(v, func(e reflect.Value) int16 { return int16(intOf(e)) }))
+		}
+	case 'I':
+		if arr = env.NewIntArray(n); arr != 0 {
+			env.SetIntArrayRegion(arr, 0, sliceOf(v, func(e reflect.Value) int32 { return int32(intOf(e)) }))
+		}
+	case 'J':
+		if arr = env.NewLongArray(n); arr != 0 {
+			env.SetLongArrayRegion(arr, 0, sliceOf(v, intOf))
+		}
+	case 'F':
+		if arr = env.NewFloatArray(n); arr != 0 {
+			env.SetFloatArrayRegion(arr, 0, sliceOf(v, func(e reflect.Value) float32 { return float32(e.Float()) }))
+		}
+	case 'D':
+		if arr = env.NewDoubleArray(n); arr != 0 {
+			env.SetDoubleArrayRegion(arr, 0, sliceOf(v, reflect.Value.Float))
+		}
+	default:
+		cls, err := env.findClass(classOfDescriptor(elem))
+		if err != nil {
+			return 0, err
+		}
+		if arr = env.NewObjectArray(n, cls, 0); arr == 0 {
+			break
+		}
+		for i := 0; i < n; i++ {
+			ref, err := env.toJava(v.Index(i), elem)
+			if err != nil {
+				env.DeleteLocalRef(arr)
+				return 0, fmt.Errorf("第 %d 个元素: %w", i, err)
+			}
+			env.SetObjectArrayElement(arr, i, ref)
+			if ref != 0 {
+				env.DeleteLocalRef(ref)
+			}
+		}
+	}
+
+	if arr == 0 {
+		return 0, env.exceptionOr("创建数组 [" + elem + " 失败")
+	}
+	return arr, nil
+}
+
+// 按照 Java 基本类型描述符把 Go 值转换为 jvalue
+func primitiveValue(v reflect.Value, p byte) Jvalue {
+	switch p {
+	case 'Z':
+		return ValueOf(v.Bool())
+	case 'B':
+		return ValueOf(int8(intOf(v)))
+	case 'C':
+		return ValueOf(uint16(intOf(v)))
+	case 'S':
+		return ValueOf(int16(intOf(v)))
+	case 'I':
+		return ValueOf(int32(intOf(v)))
+	case 'J':
+		return ValueOf(intOf(v))
+	case 'F':
+		return ValueOf(float32(v.Float()))
+	default:
+		return ValueOf(v.Float())
+	}
+}
+
+// 读取有符号或无符号整数
+func intOf(v reflect.Value) int64 {
+	if v.CanInt() {
+		return v.Int()
+	}
+	return int64(v.Uint())
+}
+
+func sliceOf[T any](v reflect.Value, conv func(reflect.Value) T) []T {
+	ret := make([]T, v.Len())
+	for i := range ret {
+		ret[i] = conv(v.Index(i))
+	}
+	return ret
+}
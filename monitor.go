@@ -0,0 +1,57 @@
+package jni
+
+import (
+	"errors"
+	"time"
+)
+
+// Synchronized 相当于 Java 的 synchronized (obj) { ... }：进入 obj 的监视器后调用 fn，
+// fn 返回或 panic 时都会退出监视器。
+func (env Env) Synchronized(obj Jobject, fn func() error) (err error) {
+	if env.MonitorEnter(obj) != JNI_OK {
+		return env.exceptionOr("MonitorEnter 失败")
+	}
+	defer func() {
+		if env.MonitorExit(obj) != JNI_OK && err == nil {
+			err = env.exceptionOr("MonitorExit 失败")
+		}
+	}()
+	return fn()
+}
+
+// Wait 调用 obj.wait(timeout)，timeout 为 0 表示一直等待，精度为毫秒。
+// 必须在 Synchronized(obj, ...) 中调用，否则返回 IllegalMonitorStateException。
+func (env Env) Wait(obj Jobject, timeout time.Duration) error {
+	if timeout < 0 {
+		return errors.New("jni: Wait 的 timeout 不能为负数")
+	}
+	// 不足 1 毫秒的等待不能变成 0（永久等待）
+	millis := timeout.Milliseconds()
+	if millis == 0 && timeout > 0 {
+		millis = 1
+	}
+
+	_, wait, err := env.methodID("java/lang/Object", "wait", "(J)V")
+	if err != nil {
+		return err
+	}
+	return env.callVoid(obj, wait, ValueOf(millis))
+}
+
+// Notify 调用 obj.notify()，必须在 Synchronized(obj, ...) 中调用
+func (env Env) Notify(obj Jobject) error {
+	_, notify, err := env.methodID("java/lang/Object", "notify", "()V")
+	if err != nil {
+		return err
+	}
+	return env.callVoid(obj, notify)
+}
+
+// NotifyAll 调用 obj.notifyAll()，必须在 Synchronized(obj, ...) 中调用
+func (env Env) NotifyAll(obj Jobject) error {
+	_, notifyAll, err := env.methodID("java/lang/Object", "notifyAll", "()V")
+	if err != nil {
+		return err
+	}
+	return env.callVoid(obj, notifyAll)
+}
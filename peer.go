@@ -0,0 +1,242 @@
+package jni
+
+//
+// #include <jni.h>
+//
+// extern void goPeerCleanup(JNIEnv *env, jobject self);
+// extern void goPeerFinalize(JNIEnv *env, jobject self);
+import "C"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/cgo"
+	"slices"
+	"sync"
+	"unsafe"
+)
+
+// peerClass 描述一个在运行时定义的 Java 类，它的实例持有一个 cgo.Handle，
+// native 方法通过这个 handle 找到对应的 Go 值。
+type peerClass struct {
+	name       string // 简短的类名，如 "GoIterator"
+	super      string
+	interfaces []string
+	methods    []peerMethod
+
+	once   sync.Once
+	class  Jclass // 全局引用
+	ctor   JmethodID
+	handle JfieldID
+	err    error
+}
+
+// 每个加载了本库的动态库使用不同的包名，避免多个 Go 动态库在同一个 JVM 中重复定义或覆盖 native 方法
+var peerPackage = fmt.Sprintf("com/github/clarkguan/jni/p%x/", reflect.ValueOf(peerClassBytes).Pointer())
+
+// 第一次使用时定义类并注册 native 方法。JVM 不支持 Cleaner 时，类中会增加 finalize 方法释放 handle。
+func (pc *peerClass) resolve(env Env) error {
+	// 在 once 之外查找 Cleaner，暂时的失败不会让 peer 类永远不可用
+	hasCleaner, err := env.resolveCleaner()
+	if err != nil {
+		return err
+	}
+
+	pc.once.Do(func() {
+		methods := pc.methods
+		if !hasCleaner {
+			methods = append(slices.Clip(methods), peerMethod{name: "finalize", sig: "()V", fn: unsafe.Pointer(C.goPeerFinalize)})
+		}
+
+		name := peerPackage + pc.name
+		local := env.DefineClass(name, 0, peerClassBytes(name, pc.super, pc.interfaces, methods))
+		if local == 0 {
+			pc.err = fmt.Errorf("jni: 定义类 %s 失败: %w", name, env.exceptionOr("DefineClass 失败"))
+			return
+		}
+		defer env.DeleteLocalRef(local)
+
+		natives := make([]NativeMethod, len(methods))
+		for i, m := range methods {
+			natives[i] = NativeMethod{Name: m.name, Signature: m.sig, FnPtr: m.fn}
+		}
+		if env.RegisterNatives(local, natives) != JNI_OK {
+			pc.err = fmt.Errorf("jni: 注册 %s 的 native 方法失败: %w", name, env.exceptionOr("RegisterNatives 失败"))
+			return
+		}
+
+		pc.ctor = env.GetMethodID(local, "<init>", "(J)V")
+		pc.handle = env.GetFieldID(local, "handle", "J")
+		if pc.ctor == 0 || pc.handle == 0 {
+			pc.err = fmt.Errorf("jni: 类 %s 不完整: %w", name, env.exceptionOr("找不到构造函数或 handle 字段"))
+			return
+		}
+		pc.class = env.NewGlobalRef(local)
+	})
+	return pc.err
+}
+
+// 创建持有 v 的 peer 对象（局部引用）。Java 对象被回收时通过 java.lang.ref.Cleaner
+// （没有 Cleaner 时通过 finalize）释放 v，如果 v 实现了 peerReleaser，同时调用其 release 方法。
+func (env Env) newPeer(pc *peerClass, v any) (Jobject, error) {
+	h, obj, err := env.newPeerObject(pc, v)
+	if err != nil {
+		return 0, err
+	}
+	// 没有注册 Cleaner 时，h 由 peer 类的 finalize 释放
+	if _, err = env.registerCleanup(obj, h); err != nil {
+		env.DeleteLocalRef(obj)
+		releaseHandle(h)
+		return 0, err
+	}
+	return obj, nil
+}
+
+func (env Env) newPeerObject(pc *peerClass, v any) (cgo.Handle, Jobject, error) {
+	if err := pc.resolve(env); err != nil {
+		return 0, 0, err
+	}
+
+	h := cgo.NewHandle(v)
+	obj := env.NewObjectA(pc.class, pc.ctor, ValueOf(int64(h)))
+	if obj == 0 {
+		h.Delete()
+		return 0, 0, env.exceptionOr("创建 " + pc.name + " 对象失败")
+	}
+	return h, obj, nil
+}
+
+// 读取 peer 对象持有的 Go 值
+func peerValue[T any](env Env, pc *peerClass, self Jobject) T {
+	return cgo.Handle(env.GetLongField(self, pc.handle)).Value().(T)
+}
+
+// peer 对象被回收时需要额外清理的 Go 值实现此接口
+type peerReleaser interface {
+	release()
+}
+
+var cleanupPeer = &peerClass{
+	name:       "GoCleanup",
+	super:      "java/lang/Object",
+	interfaces: []string{"java/lang/Runnable"},
+	methods: []peerMethod{
+		{name: "run", sig: "()V", fn: unsafe.Pointer(C.goPeerCleanup)},
+	},
+}
+
+var cleaner struct {
+	mu       sync.Mutex
+	resolved bool
+	cleaner  Jobject // 全局引用，resolved 且为 0 表示 JVM 不支持 Cleaner（Java 8 及更早版本）
+	register JmethodID
+}
+
+// 查找并创建共用的 Cleaner，返回 JVM 是否支持 Cleaner。只记住成功的结果和找不到 Cleaner 类的情况，
+// 其他失败时下次调用会重试。
+func (env Env) resolveCleaner() (bool, error) {
+	cleaner.mu.Lock()
+	defer cleaner.mu.Unlock()
+	if cleaner.resolved {
+		return cleaner.cleaner != 0, nil
+	}
+
+	cls, create, err := env.staticMethodID("java/lang/ref/Cleaner", "create", "()Ljava/lang/ref/Cleaner;")
+	var e *Exception
+	if errors.As(err, &e) && (e.ClassName == "java.lang.NoClassDefFoundError" || e.ClassName == "java.lang.ClassNotFoundException") {
+		cleaner.resolved = true
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	_, register, err := env.methodID("java/lang/ref/Cleaner", "register",
+		"(Ljava/lang/Object;Ljava/lang/Runnable;)Ljava/lang/ref/Cleaner$Cleanable;")
+	if err != nil {
+		return false, err
+	}
+	local := env.CallStaticObjectMethodA(cls, create)
+	if local == 0 {
+		return false, env.exceptionOr("Cleaner.create 失败")
+	}
+	defer env.DeleteLocalRef(local)
+
+	cleaner.cleaner = env.NewGlobalRef(local)
+	cleaner.register = register
+	cleaner.resolved = true
+	return true, nil
+}
+
+// 在 obj 被回收后释放 h，返回是否注册成功。JVM 不支持 Cleaner 时返回 false，
+// 这时 h 不会被自动释放，需要调用方自己处理。
+func (env Env) registerCleanup(obj Jobject, h cgo.Handle) (bool, error) {
+	hasCleaner, err := env.resolveCleaner()
+	if err != nil || !hasCleaner {
+		return false, err
+	}
+
+	// GoCleanup 持有的 handle 就是 h 本身，它不能再注册 Cleaner
+	if err := cleanupPeer.resolve(env); err != nil {
+		return false, err
+	}
+	action := env.NewObjectA(cleanupPeer.class, cleanupPeer.ctor, ValueOf(int64(h)))
+	if action == 0 {
+		return false, env.exceptionOr("创建 GoCleanup 对象失败")
+	}
+	defer env.DeleteLocalRef(action)
+
+	cleanable, err := env.callObject(cleaner.cleaner, cleaner.register, ValueOf(obj), ValueOf(action))
+	if err != nil {
+		return false, err
+	}
+	env.DeleteLocalRef(cleanable)
+	return true, nil
+}
+
+// 释放 handle 持有的 Go 值
+func releaseHandle(h cgo.Handle) {
+	if r, ok := h.Value().(peerReleaser); ok {
+		r.release()
+	}
+	h.Delete()
+}
+
+//export goPeerCleanup
+func goPeerCleanup(cenv *C.JNIEnv, self C.jobject) {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	releaseHandle(cgo.Handle(env.GetLongField(Jobject(self), cleanupPeer.handle)))
+}
+
+// 没有 Cleaner 的 JVM 上，peer 对象被回收时通过 finalize 释放 handle
+//
+//export goPeerFinalize
+func goPeerFinalize(cenv *C.JNIEnv, self C.jobject) {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	cls := env.GetObjectClass(Jobject(self))
+	defer env.DeleteLocalRef(cls)
+	if h := env.GetLongField(Jobject(self), env.GetFieldID(cls, "handle", "J")); h != 0 {
+		releaseHandle(cgo.Handle(h))
+	}
+}
+
+// 在 native 方法中使用：把 Go 的 panic 转换为 Java 的 RuntimeException，避免 JVM 崩溃
+func (env Env) recoverPanic() {
+	if r := recover(); r != nil {
+		env.throwNew("java/lang/RuntimeException", fmt.Sprint("go panic: ", r))
+	}
+}
+
+// 抛出 className 类型的 Java 异常
+func (env Env) throwNew(className, msg string) {
+	cls, err := env.findClass(className)
+	if err != nil {
+		if className != "java/lang/RuntimeException" {
+			env.throwNew("java/lang/RuntimeException", msg)
+		}
+		return
+	}
+	env.ThrowNew(cls, msg)
+}
@@ -0,0 +1,406 @@
+package jni
+
+//
+// #include <jni.h>
+//
+// extern jobject goProxyInvoke(JNIEnv *env, jobject self, jobject proxy, jobject method, jobjectArray args);
+import "C"
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"github.com/ClarkGuan/jni/classfile"
+)
+
+// Handler 处理 Java 代理对象上的方法调用。
+//
+// 返回值会作为 Java 方法的返回值，返回基本类型的方法需要返回对应的包装类对象（见 Env.Box），
+// void 方法返回 0 即可。返回的错误会在 Java 端抛出为 RuntimeException。
+type Handler func(env Env, call *ProxyCall) (Jobject, error)
+
+// ProxyCall 是代理对象上的一次方法调用，其中的引用都只在本次调用内有效
+type ProxyCall struct {
+	Proxy      Jobject   // 代理对象
+	Method     Jobject   // 被调用的 java.lang.reflect.Method
+	Name       string    // 方法名
+	Descriptor string    // 方法描述符，如 "(ILjava/lang/String;)V"，用于区分重载的方法
+	Default    bool      // 是否是接口的 default 方法，见 InvokeDefault
+	Args       []Jobject // 参数，基本类型参数已经装箱
+
+	array JobjectArray
+}
+
+// Scan 按照方法描述符把参数转换为 Go 值，写入 dst 中对应的指针，转换规则与 Unmarshal 的字段相同：
+// 基本类型参数拆箱为 bool、int32 等，也可以用 *int32 等指针接收；String 对应 string，
+// 数组对应切片，Jobject 得到新的局部引用。dst 中为 nil 的参数会被跳过。
+func (c *ProxyCall) Scan(env Env, dst ...any) error {
+	params, _, err := classfile.ParseMethodDescriptor(c.Descriptor)
+	if err != nil {
+		return err
+	}
+	if len(dst) != len(params) {
+		return fmt.Errorf("jni: %s%s 有 %d 个参数，Scan 接收 %d 个", c.Name, c.Descriptor, len(params), len(dst))
+	}
+
+	for i, p := range dst {
+		if p == nil {
+			continue
+		}
+		v := reflect.ValueOf(p)
+		if v.Kind() != reflect.Pointer || v.IsNil() {
+			return fmt.Errorf("jni: Scan 的第 %d 个参数应为非 nil 的指针，实际为 %T", i+1, p)
+		}
+		v = v.Elem()
+
+		// 基本类型的参数已经装箱，用指针接收时按包装类处理
+		sig := params[i]
+		if len(sig) == 1 && v.Kind() == reflect.Pointer {
+			sig = boxTypes[sig[0]].descriptor()
+		}
+		if err = checkDescriptor(v.Type(), sig); err != nil {
+			return fmt.Errorf("jni: %s%s 第 %d 个参数: %w", c.Name, c.Descriptor, i+1, err)
+		}
+		if len(sig) == 1 {
+			err = env.unbox(c.Args[i], sig[0], v)
+		} else {
+			err = env.fromJava(c.Args[i], v, sig, &unmarshalOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("jni: %s%s 第 %d 个参数: %w", c.Name, c.Descriptor, i+1, err)
+		}
+	}
+	return nil
+}
+
+// InvokeDefault 通过 InvocationHandler.invokeDefault 执行接口中 default 方法的实现，返回局部引用。
+// 需要 Java 16 及以上，更早的版本返回错误。
+func (c *ProxyCall) InvokeDefault(env Env) (Jobject, error) {
+	cls, invokeDefault, err := env.staticMethodID("java/lang/reflect/InvocationHandler", "invokeDefault",
+		"(Ljava/lang/Object;Ljava/lang/reflect/Method;[Ljava/lang/Object;)Ljava/lang/Object;")
+	if err != nil {
+		return 0, fmt.Errorf("jni: 调用 default 方法 %s 需要 Java 16 及以上: %w", c.Name, err)
+	}
+	ret := env.CallStaticObjectMethodA(cls, invokeDefault, ValueOf(c.Proxy), ValueOf(c.Method), ValueOf(c.array))
+	if err = env.CheckException(); err != nil {
+		return 0, err
+	}
+	return ret, nil
+}
+
+// Implement 通过 java.lang.reflect.Proxy 创建实现了接口 iface（如 "java/lang/Runnable"）的 Java 对象，
+// 接口方法（包括 default 方法）的调用都会转发给 handler。Object 的 equals、hashCode、toString 使用对象标识实现。
+// 返回局部引用。
+func Implement(env Env, iface string, handler Handler) (Jobject, error) {
+	cls, err := env.findClass(iface)
+	if err != nil {
+		return 0, err
+	}
+	return env.newProxy(cls, handler)
+}
+
+func (env Env) newProxy(iface Jclass, handler Handler) (Jobject, error) {
+	proxyClass, newProxyInstance, err := env.staticMethodID("java/lang/reflect/Proxy", "newProxyInstance",
+		"(Ljava/lang/ClassLoader;[Ljava/lang/Class;Ljava/lang/reflect/InvocationHandler;)Ljava/lang/Object;")
+	if err != nil {
+		return 0, err
+	}
+	_, getClassLoader, err := env.methodID("java/lang/Class", "getClassLoader", "()Ljava/lang/ClassLoader;")
+	if err != nil {
+		return 0, err
+	}
+	classClass, err := env.findClass("java/lang/Class")
+	if err != nil {
+		return 0, err
+	}
+
+	// 使用接口自身的 ClassLoader，保证代理类能看到该接口
+	loader, err := env.callObject(iface, getClassLoader)
+	if err != nil {
+		return 0, err
+	}
+	if loader != 0 {
+		defer env.DeleteLocalRef(loader)
+	}
+
+	ifaces := env.NewObjectArray(1, classClass, iface)
+	if ifaces == 0 {
+		return 0, env.exceptionOr("创建 Class 数组失败")
+	}
+	defer env.DeleteLocalRef(ifaces)
+
+	h, err := env.newPeer(proxyPeer, &goProxy{handler: handler})
+	if err != nil {
+		return 0, err
+	}
+	defer env.DeleteLocalRef(h)
+
+	proxy := env.CallStaticObjectMethodA(proxyClass, newProxyInstance, ValueOf(loader), ValueOf(ifaces), ValueOf(h))
+	if proxy == 0 {
+		return 0, env.exceptionOr("Proxy.newProxyInstance 失败")
+	}
+	return proxy, nil
+}
+
+// NewRunnable 创建调用 fn 的 java.lang.Runnable
+func NewRunnable(env Env, fn func(env Env) error) (Jobject, error) {
+	return Implement(env, "java/lang/Runnable", functional("run", func(env Env, call *ProxyCall) (Jobject, error) {
+		return 0, fn(env)
+	}))
+}
+
+// NewCallable 创建调用 fn 的 java.util.concurrent.Callable，返回值通过 encode 转换
+func NewCallable[R any](env Env, fn func(env Env) (R, error), encode Encoder[R]) (Jobject, error) {
+	return Implement(env, "java/util/concurrent/Callable", functional("call", func(env Env, call *ProxyCall) (Jobject, error) {
+		r, err := fn(env)
+		if err != nil {
+			return 0, err
+		}
+		return encode(env, r)
+	}))
+}
+
+// NewSupplier 创建调用 fn 的 java.util.function.Supplier，返回值通过 encode 转换
+func NewSupplier[R any](env Env, fn func(env Env) (R, error), encode Encoder[R]) (Jobject, error) {
+	return Implement(env, "java/util/function/Supplier", functional("get", func(env Env, call *ProxyCall) (Jobject, error) {
+		r, err := fn(env)
+		if err != nil {
+			return 0, err
+		}
+		return encode(env, r)
+	}))
+}
+
+// NewConsumer 创建调用 fn 的 java.util.function.Consumer，参数通过 decode 转换
+func NewConsumer[T any](env Env, fn func(env Env, v T) error, decode Decoder[T]) (Jobject, error) {
+	return Implement(env, "java/util/function/Consumer", functional("accept", func(env Env, call *ProxyCall) (Jobject, error) {
+		v, err := decode(env, call.Args[0])
+		if err != nil {
+			return 0, err
+		}
+		return 0, fn(env, v)
+	}))
+}
+
+// NewFunction 创建调用 fn 的 java.util.function.Function，参数通过 decode 转换，返回值通过 encode 转换
+func NewFunction[T, R any](env Env, fn func(env Env, v T) (R, error), decode Decoder[T], encode Encoder[R]) (Jobject, error) {
+	return Implement(env, "java/util/function/Function", functional("apply", func(env Env, call *ProxyCall) (Jobject, error) {
+		v, err := decode(env, call.Args[0])
+		if err != nil {
+			return 0, err
+		}
+		r, err := fn(env, v)
+		if err != nil {
+			return 0, err
+		}
+		return encode(env, r)
+	}))
+}
+
+// 只把函数式接口的抽象方法 name 转发给 handler。
+// default 方法（如 Consumer.andThen、Function.compose）也会经过 InvocationHandler，
+// 它们的参数和返回值与 name 不同，通过 InvokeDefault 执行接口自身的实现。
+func functional(name string, handler Handler) Handler {
+	return func(env Env, call *ProxyCall) (Jobject, error) {
+		if call.Default {
+			return call.InvokeDefault(env)
+		}
+		if call.Name != name {
+			return 0, fmt.Errorf("jni: 不支持调用 %s 方法", call.Name)
+		}
+		return handler(env, call)
+	}
+}
+
+type goProxy struct {
+	handler Handler
+}
+
+var proxyPeer = &peerClass{
+	name:       "GoInvocationHandler",
+	super:      "java/lang/Object",
+	interfaces: []string{"java/lang/reflect/InvocationHandler"},
+	methods: []peerMethod{
+		{
+			name: "invoke",
+			sig:  "(Ljava/lang/Object;Ljava/lang/reflect/Method;[Ljava/lang/Object;)Ljava/lang/Object;",
+			fn:   unsafe.Pointer(C.goProxyInvoke),
+		},
+	},
+}
+
+//export goProxyInvoke
+func goProxyInvoke(cenv *C.JNIEnv, self, proxy, method C.jobject, args C.jobjectArray) C.jobject {
+	env := Env(unsafe.Pointer(cenv))
+	defer env.recoverPanic()
+
+	ret, err := env.invokeProxy(peerValue[*goProxy](env, proxyPeer, Jobject(self)), Jobject(proxy), Jobject(method), JobjectArray(args))
+	if err != nil {
+		env.throwNew("java/lang/RuntimeException", err.Error())
+		return 0
+	}
+	return C.jobject(ret)
+}
+
+func (env Env) invokeProxy(p *goProxy, proxy, method Jobject, array JobjectArray) (Jobject, error) {
+	call, err := env.proxyCall(proxy, method, array)
+	defer func() {
+		for _, arg := range call.Args {
+			if arg != 0 {
+				env.DeleteLocalRef(arg)
+			}
+		}
+	}()
+	if err != nil {
+		return 0, err
+	}
+
+	if ret, ok, err := env.objectMethod(call); ok {
+		return ret, err
+	}
+	return p.handler(env, call)
+}
+
+func (env Env) proxyCall(proxy, method Jobject, array JobjectArray) (*ProxyCall, error) {
+	call := &ProxyCall{Proxy: proxy, Method: method, array: array}
+
+	_, getName, err := env.methodID("java/lang/reflect/Method", "getName", "()Ljava/lang/String;")
+	if err != nil {
+		return call, err
+	}
+	_, isDefault, err := env.methodID("java/lang/reflect/Method", "isDefault", "()Z")
+	if err != nil {
+		return call, err
+	}
+	str, err := env.callObject(method, getName)
+	if err != nil {
+		return call, err
+	}
+	call.Name = env.goString(str)
+	env.DeleteLocalRef(str)
+	if call.Descriptor, err = env.methodDescriptor(method); err != nil {
+		return call, err
+	}
+	if call.Default, err = env.callBoolean(method, isDefault); err != nil {
+		return call, err
+	}
+
+	for _, arg := range IterateArray(env, array) {
+		call.Args = append(call.Args, env.NewLocalRef(arg))
+	}
+	return call, nil
+}
+
+// 基本类型的 Class.getName() 对应的描述符
+var primitiveDescriptors = map[string]string{
+	"boolean": "Z",
+	"byte":    "B",
+	"char":    "C",
+	"short":   "S",
+	"int":     "I",
+	"long":    "J",
+	"float":   "F",
+	"double":  "D",
+	"void":    "V",
+}
+
+// 返回 java.lang.reflect.Method 的方法描述符
+func (env Env) methodDescriptor(method Jobject) (string, error) {
+	_, getParameterTypes, err := env.methodID("java/lang/reflect/Method", "getParameterTypes", "()[Ljava/lang/Class;")
+	if err != nil {
+		return "", err
+	}
+	_, getReturnType, err := env.methodID("java/lang/reflect/Method", "getReturnType", "()Ljava/lang/Class;")
+	if err != nil {
+		return "", err
+	}
+
+	params, err := env.callObject(method, getParameterTypes)
+	if err != nil {
+		return "", err
+	}
+	defer env.DeleteLocalRef(params)
+
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, cls := range IterateArray(env, params) {
+		desc, err := env.classDescriptor(cls)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(desc)
+	}
+	sb.WriteByte(')')
+
+	ret, err := env.callObject(method, getReturnType)
+	if err != nil {
+		return "", err
+	}
+	defer env.DeleteLocalRef(ret)
+	desc, err := env.classDescriptor(ret)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(desc)
+	return sb.String(), nil
+}
+
+// 返回 Class 对象对应的字段描述符，void.class 返回 "V"
+func (env Env) classDescriptor(cls Jclass) (string, error) {
+	_, getName, err := env.methodID("java/lang/Class", "getName", "()Ljava/lang/String;")
+	if err != nil {
+		return "", err
+	}
+	str, err := env.callObject(cls, getName)
+	if err != nil {
+		return "", err
+	}
+	defer env.DeleteLocalRef(str)
+
+	// 数组的名称已经是描述符，只是用 '.' 分隔，如 "[Ljava.lang.String;"
+	name := env.goString(str)
+	if desc, ok := primitiveDescriptors[name]; ok {
+		return desc, nil
+	}
+	name = strings.ReplaceAll(name, ".", "/")
+	if strings.HasPrefix(name, "[") {
+		return name, nil
+	}
+	return "L" + name + ";", nil
+}
+
+// 处理 java.lang.Object 中的 equals、hashCode 和 toString
+func (env Env) objectMethod(call *ProxyCall) (ret Jobject, ok bool, err error) {
+	switch call.Name + call.Descriptor {
+	case "equals(Ljava/lang/Object;)Z":
+		ret, err = env.box('Z', ValueOf(env.IsSameObject(call.Proxy, call.Args[0])))
+		return ret, true, err
+
+	case "hashCode()I":
+		hash, err := env.identityHashCode(call.Proxy)
+		if err != nil {
+			return 0, true, err
+		}
+		ret, err = env.box('I', ValueOf(hash))
+		return ret, true, err
+
+	case "toString()Ljava/lang/String;":
+		hash, err := env.identityHashCode(call.Proxy)
+		if err != nil {
+			return 0, true, err
+		}
+		ret, err = env.javaString(fmt.Sprintf("GoProxy@%x", uint32(hash)))
+		return ret, true, err
+	}
+	return 0, false, nil
+}
+
+func (env Env) identityHashCode(obj Jobject) (int32, error) {
+	cls, identityHashCode, err := env.staticMethodID("java/lang/System", "identityHashCode", "(Ljava/lang/Object;)I")
+	if err != nil {
+		return 0, err
+	}
+	hash := env.CallStaticIntMethodA(cls, identityHashCode, ValueOf(obj))
+	return int32(hash), env.CheckException()
+}
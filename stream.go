@@ -0,0 +1,214 @@
+package jni
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// 与 Java 交换数据时使用的 byte[] 大小
+const streamBufferSize = 32 * 1024
+
+// Reader 把 java.io.InputStream 包装为 io.Reader，实现了 io.Reader、io.WriterTo 和 io.Closer。
+// Reader 持有全局引用，可以在任意 goroutine 中使用，必要时会把当前线程附加到 JVM。
+// Java 抛出的 IOException 等异常以 *Exception 返回。
+type Reader struct {
+	mu     sync.Mutex
+	vm     VM
+	stream Jobject    // 全局引用
+	buf    JbyteArray // 全局引用，所有读操作共用
+	read   JmethodID
+	close  JmethodID
+}
+
+// NewReader 创建读取 in 的 Reader，使用完成后需要调用 Close
+func NewReader(env Env, in Jobject) (*Reader, error) {
+	_, read, err := env.methodID("java/io/InputStream", "read", "([BII)I")
+	if err != nil {
+		return nil, err
+	}
+	_, closeID, err := env.methodID("java/io/InputStream", "close", "()V")
+	if err != nil {
+		return nil, err
+	}
+	vm, stream, buf, err := env.newStream(in)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{vm: vm, stream: stream, buf: buf, read: read, close: closeID}, nil
+}
+
+func (r *Reader) Read(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stream == 0 {
+		return 0, errStreamClosed
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	err = r.vm.attach(func(env Env) error {
+		n, err = r.readChunk(env, p)
+		return err
+	})
+	return n, err
+}
+
+// WriteTo 把 InputStream 剩余的数据全部写入 w，整个过程只附加一次线程
+func (r *Reader) WriteTo(w io.Writer) (written int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stream == 0 {
+		return 0, errStreamClosed
+	}
+
+	err = r.vm.attach(func(env Env) error {
+		p := make([]byte, streamBufferSize)
+		for {
+			n, err := r.readChunk(env, p)
+			if n > 0 {
+				m, werr := w.Write(p[:n])
+				written += int64(m)
+				if werr != nil {
+					return werr
+				}
+				if m < n {
+					return io.ErrShortWrite
+				}
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	})
+	return written, err
+}
+
+// 调用一次 InputStream.read(byte[], int, int)
+func (r *Reader) readChunk(env Env, p []byte) (int, error) {
+	n, err := env.callInt(r.stream, r.read, ValueOf(r.buf), ValueOf(0), ValueOf(min(len(p), streamBufferSize)))
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, io.EOF
+	}
+	env.GetByteArrayRegion(r.buf, 0, p[:n])
+	return n, nil
+}
+
+// Close 关闭 InputStream 并释放全局引用，多次调用是安全的
+func (r *Reader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return closeStream(r.vm, &r.stream, &r.buf, r.close)
+}
+
+// Writer 把 java.io.OutputStream 包装为 io.Writer，实现了 io.Writer 和 io.Closer。
+// 与 Reader 一样可以在任意 goroutine 中使用。
+type Writer struct {
+	mu     sync.Mutex
+	vm     VM
+	stream Jobject    // 全局引用
+	buf    JbyteArray // 全局引用，所有写操作共用
+	write  JmethodID
+	flush  JmethodID
+	close  JmethodID
+}
+
+// NewWriter 创建写入 out 的 Writer，使用完成后需要调用 Close
+func NewWriter(env Env, out Jobject) (*Writer, error) {
+	_, write, err := env.methodID("java/io/OutputStream", "write", "([BII)V")
+	if err != nil {
+		return nil, err
+	}
+	_, flush, err := env.methodID("java/io/OutputStream", "flush", "()V")
+	if err != nil {
+		return nil, err
+	}
+	_, closeID, err := env.methodID("java/io/OutputStream", "close", "()V")
+	if err != nil {
+		return nil, err
+	}
+	vm, stream, buf, err := env.newStream(out)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{vm: vm, stream: stream, buf: buf, write: write, flush: flush, close: closeID}, nil
+}
+
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stream == 0 {
+		return 0, errStreamClosed
+	}
+
+	err = w.vm.attach(func(env Env) error {
+		for n < len(p) {
+			chunk := p[n:min(len(p), n+streamBufferSize)]
+			env.SetByteArrayRegion(w.buf, 0, chunk)
+			if err := env.callVoid(w.stream, w.write, ValueOf(w.buf), ValueOf(0), ValueOf(len(chunk))); err != nil {
+				return err
+			}
+			n += len(chunk)
+		}
+		return nil
+	})
+	return n, err
+}
+
+// Flush 调用 OutputStream.flush()
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stream == 0 {
+		return errStreamClosed
+	}
+	return w.vm.attach(func(env Env) error {
+		return env.callVoid(w.stream, w.flush)
+	})
+}
+
+// Close 关闭 OutputStream 并释放全局引用，多次调用是安全的
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return closeStream(w.vm, &w.stream, &w.buf, w.close)
+}
+
+var errStreamClosed = errors.New("jni: 流已关闭")
+
+// 为 Reader 和 Writer 创建 stream 的全局引用和共用的 byte[]
+func (env Env) newStream(stream Jobject) (VM, Jobject, JbyteArray, error) {
+	if stream == 0 {
+		return 0, 0, 0, errors.New("jni: 流对象为 null")
+	}
+	vm, err := env.vm()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	local := env.NewByteArray(streamBufferSize)
+	if local == 0 {
+		return 0, 0, 0, env.exceptionOr("创建 byte[] 失败")
+	}
+	defer env.DeleteLocalRef(local)
+	return vm, env.NewGlobalRef(stream), env.NewGlobalRef(local), nil
+}
+
+func closeStream(vm VM, stream *Jobject, buf *JbyteArray, closeID JmethodID) error {
+	if *stream == 0 {
+		return nil
+	}
+	return vm.attach(func(env Env) error {
+		err := env.callVoid(*stream, closeID)
+		env.DeleteGlobalRef(*stream)
+		env.DeleteGlobalRef(*buf)
+		*stream, *buf = 0, 0
+		return err
+	})
+}
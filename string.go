@@ -0,0 +1,49 @@
+package jni
+
+//
+// #include <jni.h>
+//
+// static inline void getStringRegion(JNIEnv *env, jstring str, jsize len, jchar *buf) {
+//     (*env)->GetStringRegion(env, str, 0, len, buf);
+// }
+import "C"
+import (
+	"unicode/utf16"
+	"unsafe"
+)
+
+// 把 Java 字符串转换为 Go 字符串，null 转换为 ""。
+// 不使用 GetStringUTF：modified UTF-8 中补充平面的字符是两个代理项，'\0' 是 C0 80，都不是合法的 UTF-8。
+func (env Env) goString(s Jstring) string {
+	if s == 0 {
+		return ""
+	}
+	n := env.GetStringLength(s)
+	if n == 0 {
+		return ""
+	}
+	codes := make([]uint16, n)
+	C.getStringRegion((*C.JNIEnv)(unsafe.Pointer(env)), C.jstring(s), C.jsize(n), (*C.jchar)(unsafe.Pointer(unsafe.SliceData(codes))))
+	return string(utf16.Decode(codes))
+}
+
+// 把 Go 字符串转换为 Java 字符串。
+// 与 NewString 不同，空字符串会得到 Java 的 "" 而不是 null。
+func (env Env) javaString(s string) (Jstring, error) {
+	if s != "" {
+		if str := env.NewString(s); str != 0 {
+			return str, nil
+		}
+		return 0, env.exceptionOr("NewString 失败")
+	}
+
+	cls, ctor, err := env.methodID("java/lang/String", "<init>", "()V")
+	if err != nil {
+		return 0, err
+	}
+	str := env.NewObjectA(cls, ctor)
+	if str == 0 {
+		return 0, env.exceptionOr("创建空字符串失败")
+	}
+	return str, nil
+}
@@ -0,0 +1,208 @@
+package jni
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// 结构体字段与 Java 字段的对应关系，由 `jni:"name[,descriptor]"` 标签描述：
+//
+//	Name    string   `jni:"name"`
+//	Address *Address `jni:"address,Lcom/demo/Address;"`
+//	Ignored int      `jni:"-"`
+//
+// 没有标签的字段会被忽略。除嵌套结构体外，描述符都可以从 Go 类型推导出来。
+type structField struct {
+	index  int       // 在 Go 结构体中的下标
+	name   string    // Java 字段名
+	sig    string    // Java 字段描述符
+	id     JfieldID  // Java 字段 ID，使用 getter 时为 0
+	getter JmethodID // getFoo()/isFoo() 方法 ID，直接访问字段时为 0
+}
+
+type structInfo struct {
+	class  Jclass    // 全局引用
+	ctor   JmethodID // 无参构造函数，没有时为 0
+	fields []structField
+}
+
+type structKey struct {
+	typ     reflect.Type
+	class   string
+	getters bool
+}
+
+// 每个 (Go 类型, Java 类) 只解析一次
+var structCache sync.Map // structKey -> *structInfo
+
+// getters 为 true 时通过 getFoo()/isFoo() 读取字段，而不是直接访问字段
+func (env Env) structInfoOf(t reflect.Type, className string, getters bool) (*structInfo, error) {
+	key := structKey{t, className, getters}
+	if info, ok := structCache.Load(key); ok {
+		return info.(*structInfo), nil
+	}
+
+	cls, err := env.findClass(className)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &structInfo{class: cls}
+	if _, ctor, err := env.methodID(className, "<init>", "()V"); err == nil {
+		info.ctor = ctor
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, sig, ok := parseTag(sf.Tag.Get("jni"))
+		if !ok || !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		if sig == "" {
+			if sig, err = descriptorOf(sf.Type); err != nil {
+				return nil, fmt.Errorf("jni: %s.%s: %w", t, sf.Name, err)
+			}
+		} else if err = checkDescriptor(sf.Type, sig); err != nil {
+			return nil, fmt.Errorf("jni: %s.%s: %w", t, sf.Name, err)
+		}
+
+		f := structField{index: i, name: name, sig: sig}
+		if getters {
+			f.getter, err = env.getterID(className, name, sig)
+		} else {
+			_, f.id, err = env.fieldID(className, name, sig)
+		}
+		if err != nil {
+			return nil, err
+		}
+		info.fields = append(info.fields, f)
+	}
+
+	actual, _ := structCache.LoadOrStore(key, info)
+	return actual.(*structInfo), nil
+}
+
+// 按照 JavaBean 的约定查找 getter：boolean 字段优先使用 isFoo()，其余使用 getFoo()
+func (env Env) getterID(className, name, sig string) (JmethodID, error) {
+	suffix := strings.ToUpper(name[:1]) + name[1:]
+	if sig == "Z" {
+		if _, id, err := env.methodID(className, "is"+suffix, "()Z"); err == nil {
+			return id, nil
+		}
+	}
+	_, id, err := env.methodID(className, "get"+suffix, "()"+sig)
+	return id, err
+}
+
+// 解析 `jni:"name,descriptor"`，ok 为 false 表示忽略该字段
+func parseTag(tag string) (name, sig string, ok bool) {
+	if tag == "" || tag == "-" {
+		return "", "", false
+	}
+	name, sig, _ = strings.Cut(tag, ",")
+	return strings.TrimSpace(name), strings.TrimSpace(sig), true
+}
+
+// Go 基本类型对应的 Java 基本类型描述符
+var primitiveKinds = map[reflect.Kind]byte{
+	reflect.Bool:    'Z',
+	reflect.Int8:    'B',
+	reflect.Uint8:   'B',
+	reflect.Uint16:  'C',
+	reflect.Int16:   'S',
+	reflect.Int32:   'I',
+	reflect.Int:     'I',
+	reflect.Int64:   'J',
+	reflect.Float32: 'F',
+	reflect.Float64: 'D',
+}
+
+// 从 Go 类型推导 Java 字段描述符
+func descriptorOf(t reflect.Type) (string, error) {
+	if p, ok := primitiveKinds[t.Kind()]; ok {
+		return string(p), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "Ljava/lang/String;", nil
+
+	case reflect.Uintptr:
+		return "Ljava/lang/Object;", nil
+
+	case reflect.Slice:
+		elem, err := descriptorOf(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "[" + elem, nil
+
+	case reflect.Pointer:
+		if p, ok := primitiveKinds[t.Elem().Kind()]; ok {
+			return boxTypes[p].descriptor(), nil
+		}
+		return descriptorOf(t.Elem())
+
+	case reflect.Struct:
+		return "", fmt.Errorf("结构体 %s 需要在标签中指定 Java 类型，如 `jni:\"name,Lcom/demo/Foo;\"`", t)
+	}
+
+	return "", fmt.Errorf("不支持的类型 %s", t)
+}
+
+// 检查 Go 类型能否与 Java 字段描述符互相转换
+func checkDescriptor(t reflect.Type, sig string) error {
+	if p, ok := primitiveKinds[t.Kind()]; ok {
+		if sig != string(p) {
+			return fmt.Errorf("%s 与 Java 类型 %s 不匹配，应为 %c", t, sig, p)
+		}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if sig == "Ljava/lang/String;" {
+			return nil
+		}
+
+	case reflect.Uintptr:
+		if isReferenceDescriptor(sig) {
+			return nil
+		}
+
+	case reflect.Slice:
+		if strings.HasPrefix(sig, "[") {
+			return checkDescriptor(t.Elem(), sig[1:])
+		}
+
+	case reflect.Pointer:
+		if p, ok := primitiveKinds[t.Elem().Kind()]; ok {
+			if sig == boxTypes[p].descriptor() {
+				return nil
+			}
+			return fmt.Errorf("%s 与 Java 类型 %s 不匹配，应为 %s", t, sig, boxTypes[p].descriptor())
+		}
+		return checkDescriptor(t.Elem(), sig)
+
+	case reflect.Struct:
+		if strings.HasPrefix(sig, "L") && strings.HasSuffix(sig, ";") {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s 与 Java 类型 %s 不匹配", t, sig)
+}
+
+// "Lcom/demo/Foo;" -> "com/demo/Foo"，数组描述符原样返回（FindClass 接受数组描述符）
+func classOfDescriptor(sig string) string {
+	if strings.HasPrefix(sig, "L") && strings.HasSuffix(sig, ";") {
+		return sig[1 : len(sig)-1]
+	}
+	return sig
+}
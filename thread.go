@@ -0,0 +1,114 @@
+package jni
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// CallWithContext 在当前线程中调用 fn，ctx 结束时对当前 Java 线程调用 Thread.interrupt()。
+//
+// 如果 fn 因此返回了 java.lang.InterruptedException（或 ClosedByInterruptException、InterruptedIOException），
+// 返回的错误同时包装 ctx.Err() 和原来的异常，可以使用 errors.Is(err, context.Canceled) 判断。
+// CallWithContext 返回前会清除线程的中断状态。ctx 已经结束时不调用 fn，直接返回 ctx.Err()。
+func (env Env) CallWithContext(ctx context.Context, fn func(env Env) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	vm, err := env.vm()
+	if err != nil {
+		return err
+	}
+	cls, currentThread, err := env.staticMethodID("java/lang/Thread", "currentThread", "()Ljava/lang/Thread;")
+	if err != nil {
+		return err
+	}
+	_, interrupt, err := env.methodID("java/lang/Thread", "interrupt", "()V")
+	if err != nil {
+		return err
+	}
+	_, interrupted, err := env.staticMethodID("java/lang/Thread", "interrupted", "()Z")
+	if err != nil {
+		return err
+	}
+
+	local := env.CallStaticObjectMethodA(cls, currentThread)
+	if local == 0 {
+		return env.exceptionOr("Thread.currentThread 失败")
+	}
+	thread := env.NewGlobalRef(local)
+	env.DeleteLocalRef(local)
+	defer env.DeleteGlobalRef(thread)
+
+	// 中断只能在 fn 执行期间发生，返回前等待 watcher 结束
+	stop := make(chan struct{})
+	fired := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			vm.attach(func(env Env) error {
+				env.CallVoidMethodA(thread, interrupt)
+				env.ExceptionClear()
+				return nil
+			})
+			fired <- true
+		case <-stop:
+			fired <- false
+		}
+	}()
+
+	err = fn(env)
+	close(stop)
+	if <-fired {
+		env.CallStaticBooleanMethodA(cls, interrupted)
+		env.ExceptionClear()
+		if isInterrupt(err) {
+			return fmt.Errorf("%w (%w)", ctx.Err(), err)
+		}
+	}
+	return err
+}
+
+// 判断 err 是否为线程被中断导致的 Java 异常
+func isInterrupt(err error) bool {
+	var e *Exception
+	if !errors.As(err, &e) {
+		return false
+	}
+	switch e.ClassName {
+	case "java.lang.InterruptedException", "java.nio.channels.ClosedByInterruptException", "java.io.InterruptedIOException":
+		return true
+	}
+	return false
+}
+
+// 在当前 goroutine 中获取 vm 的 Env 并调用 fn。
+// 当前线程没有附加到 JVM 时，会锁定 OS 线程并附加，fn 返回后再分离。
+func (vm VM) attach(fn func(env Env) error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	env, ret := vm.GetEnv(JNI_VERSION_1_6)
+	switch ret {
+	case JNI_OK:
+		return fn(env)
+	case JNI_EDETACHED:
+		if env, ret = vm.AttachCurrentThreadAsDaemon(); ret != JNI_OK {
+			return fmt.Errorf("jni: 附加线程失败: %d", ret)
+		}
+		defer vm.DetachCurrentThread()
+		return fn(env)
+	default:
+		return fmt.Errorf("jni: 获取 JNIEnv 失败: %d", ret)
+	}
+}
+
+// 返回 env 所属的 VM
+func (env Env) vm() (VM, error) {
+	vm, ret := env.GetJavaVM()
+	if ret != JNI_OK {
+		return 0, errors.New("jni: GetJavaVM 失败")
+	}
+	return vm, nil
+}
@@ -8,19 +8,35 @@ import (
 
 // 表示 C 语言的类型
 type cType struct {
-	isPtr     bool
-	isConst   bool
-	typeName  string
+	typeName  string    // 基础类型，如 jint、unsigned char、struct JNINativeInterface_
+	isConst   bool      // 基础类型带 const
+	ptrs      []bool    // 每一级指针，true 表示这一级指针本身带 const
+	dims      []string  // 数组各维的长度，没有指定长度时为空字符串
+	fn        *funcType // 不为 nil 时表示函数，ptrs 是函数指针的层数
 	isVarArgs bool
 }
 
+// 表示 C 语言的函数类型
+type funcType struct {
+	ret    cType
+	params []*decl
+}
+
 func (c *cType) String() string {
-	return fmt.Sprintf("{isPtr: %t, isConst: %t, typeName: %s, isVarArgs: %t}",
-		c.isPtr, c.isConst, c.typeName, c.isVarArgs)
+	if c.fn != nil {
+		return fmt.Sprintf("{ptrs: %v, ret: %s, params: %d}", c.ptrs, &c.fn.ret, len(c.fn.params))
+	}
+	return fmt.Sprintf("{typeName: %s, isConst: %t, ptrs: %v, dims: %v, isVarArgs: %t}",
+		c.typeName, c.isConst, c.ptrs, c.dims, c.isVarArgs)
+}
+
+// 是否是指向基础类型的一级指针
+func (c *cType) isPtr() bool {
+	return len(c.ptrs) == 1 && len(c.dims) == 0 && c.fn == nil
 }
 
 func (c *cType) isVoid() bool {
-	return !c.isPtr && c.typeName == "void"
+	return len(c.ptrs) == 0 && len(c.dims) == 0 && c.fn == nil && c.typeName == "void"
 }
 
 func (c *cType) toC() *cTypeOutput {
@@ -33,10 +49,11 @@ func (c *cType) toGo() *cTypeGoOutput {
 
 type cTypeOutput cType
 
+// 包装函数不需要 const，返回值的 const 在 beforeReturn 中去掉
 func (output *cTypeOutput) TypeDesc() string {
 	s := output.typeName
-	if output.isPtr {
-		s += " *"
+	if n := len(output.ptrs); n > 0 {
+		s += " " + strings.Repeat("*", n)
 	}
 	return s
 }
@@ -44,7 +61,8 @@ func (output *cTypeOutput) TypeDesc() string {
 type cTypeGoOutput cType
 
 func (output *cTypeGoOutput) TypeDesc() string {
-	if output.isPtr {
+	n := len(output.ptrs)
+	if (*cType)(output).isPtr() {
 		switch output.typeName {
 		case "JNIEnv":
 			return "Env"
@@ -58,7 +76,7 @@ func (output *cTypeGoOutput) TypeDesc() string {
 		case "char":
 			return "string"
 		}
-	} else {
+	} else if n == 0 {
 		switch output.typeName {
 		case "void":
 			return ""
@@ -98,11 +116,50 @@ func (output *cTypeGoOutput) TypeDesc() string {
 		}
 	}
 
-	s := "C." + output.typeName
-	if output.isPtr {
-		s = "*" + s
+	s := "C." + cgoName(output.typeName)
+	if output.typeName == "void" && n > 0 {
+		s = "unsafe.Pointer"
+		n--
 	}
-	return s
+	return strings.Repeat("*", n) + s
+}
+
+// 由多个关键字组成的类型在 cgo 中的名称
+var cgoNames = map[string]string{
+	"signed char":        "schar",
+	"unsigned char":      "uchar",
+	"unsigned short":     "ushort",
+	"unsigned int":       "uint",
+	"unsigned long":      "ulong",
+	"long long":          "longlong",
+	"unsigned long long": "ulonglong",
+}
+
+func cgoName(typeName string) string {
+	if name, ok := cgoNames[typeName]; ok {
+		return name
+	}
+	// struct X 在 cgo 中是 C.struct_X
+	return strings.Replace(typeName, " ", "_", 1)
+}
+
+// Go 类型的零值，用于提前返回
+func (output *cTypeGoOutput) zeroValue() string {
+	switch output.TypeDesc() {
+	case "bool":
+		return "false"
+
+	case "string":
+		return `""`
+
+	case "Env", "VM":
+		return "0"
+	}
+
+	if len(output.ptrs) > 0 {
+		return "nil"
+	}
+	return "0"
 }
 
 // 表示 C 语言的参数
@@ -122,7 +179,7 @@ func (p *param) toGo() *paramGoOutput {
 type paramOutput param
 
 func (param *paramOutput) paramDesc() string {
-	if param.isPtr && param.typeName == "jboolean" && param.idName == "isCopy" {
+	if param.isPtr() && param.typeName == "jboolean" && param.idName == "isCopy" {
 		return ""
 	}
 
@@ -130,7 +187,7 @@ func (param *paramOutput) paramDesc() string {
 }
 
 func (param *paramOutput) callDesc() string {
-	if param.isPtr && param.typeName == "jboolean" && param.idName == "isCopy" {
+	if param.isPtr() && param.typeName == "jboolean" && param.idName == "isCopy" {
 		return "NULL"
 	}
 
@@ -140,7 +197,7 @@ func (param *paramOutput) callDesc() string {
 type paramGoOutput param
 
 func (param *paramGoOutput) paramDesc() string {
-	if param.isPtr && param.typeName == "jboolean" && param.idName == "isCopy" {
+	if param.isPtr() && param.typeName == "jboolean" && param.idName == "isCopy" {
 		return ""
 	}
 
@@ -148,7 +205,7 @@ func (param *paramGoOutput) paramDesc() string {
 }
 
 func (param *paramGoOutput) paramListDesc() string {
-	if param.isPtr {
+	if param.isPtr() {
 		switch param.typeName {
 		case "jboolean":
 			return param.idName + " []bool"
@@ -180,7 +237,7 @@ func (param *paramGoOutput) paramListDesc() string {
 }
 
 func (param *paramGoOutput) paramListWrapper() string {
-	if param.isPtr {
+	if param.isPtr() {
 		switch param.typeName {
 		case "jboolean":
 			return "cBooleanArray"
@@ -211,7 +268,7 @@ func (param *paramGoOutput) paramListWrapper() string {
 }
 
 func (param *paramGoOutput) callDesc() string {
-	if param.isPtr {
+	if param.isPtr() {
 		switch param.typeName {
 		case "JNIEnv":
 			return fmt.Sprintf("(*C.JNIEnv)(unsafe.Pointer(%s))", param.idName)
@@ -230,7 +287,7 @@ func (param *paramGoOutput) callDesc() string {
 
 	}
 
-	if !param.isPtr {
+	if !param.isPtr() {
 		switch param.typeName {
 		case "jboolean":
 			return fmt.Sprintf("cbool(%s)", param.idName)
@@ -285,6 +342,11 @@ func (m *method) isVaList() bool {
 	return false
 }
 
+// 是否为不定参数（... 或 va_list）的函数
+func (m *method) isVariadic() bool {
+	return m.isVarArgs() || m.isVaList()
+}
+
 func (m *method) String() string {
 	return fmt.Sprintf("{name: %s, ret: %s, params: %v}", m.name, m.ret, m.params)
 }
@@ -361,7 +423,7 @@ func (output *methodGoOutput) isCallFunc() bool {
 	lastParam := output.params[len(output.params)-1]
 	return (strings.HasPrefix(output.name, "Call") || strings.HasPrefix(output.name, "New")) &&
 		strings.HasSuffix(output.name, "A") &&
-		lastParam.isPtr && lastParam.typeName == "jvalue"
+		lastParam.isPtr() && lastParam.typeName == "jvalue"
 }
 
 func (output *methodGoOutput) paramList() string {
@@ -447,14 +509,14 @@ func (output *methodGoOutput) callList() string {
 
 func (output *methodGoOutput) beforeReturn(in string) string {
 	// char * -> string
-	if output.ret.isPtr {
+	if output.ret.isPtr() {
 		switch output.ret.typeName {
 		case "char":
 			return fmt.Sprintf("C.GoString(%s)", in)
 		}
 	}
 
-	if !output.ret.isPtr {
+	if !output.ret.isPtr() {
 		switch output.ret.typeName {
 		case "jboolean":
 			return fmt.Sprintf("%s != C.JNI_FALSE", in)
@@ -496,7 +558,7 @@ func (output *methodGoOutput) beforeReturn(in string) string {
 
 func (output *methodGoOutput) prepareReturn(buf *bytes.Buffer) {
 	for _, p := range output.params {
-		if p.isPtr && p.typeName == "char" {
+		if p.isPtr() && p.typeName == "char" {
 			fmt.Fprintf(buf, "\tcstr_%s := C.CString(%s)\n", p.idName, p.idName)
 			fmt.Fprintf(buf, "\tdefer C.free(unsafe.Pointer(cstr_%s))\n", p.idName)
 		}
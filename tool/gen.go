@@ -2,14 +2,27 @@ package tool
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 )
 
-func GenerateCode(pkg string) string {
-	return generateCode(pkg, parseJniMethodList(headerCode))
+// GenerateCode 根据 jni.h 中的函数表生成 env.go。
+// 不定参数的函数（见 Header.VarArgs）会被跳过，其他函数无法生成时返回错误。
+func GenerateCode(pkg string, h *Header) (string, error) {
+	return generateCode(pkg, h.methods)
 }
 
-func generateCode(pkg string, list []*method) string {
+func generateCode(pkg string, list []*method) (string, error) {
+	// 不定参数和 va_list 的函数无法通过 cgo 调用，使用对应的 A 版本（参数为 jvalue 数组）代替
+	var fixed []*method
+	for _, m := range list {
+		if !m.isVariadic() {
+			fixed = append(fixed, m)
+		}
+	}
+	list = fixed
+
+	var errs []error
 	buf := bytes.NewBuffer(nil)
 
 	fmt.Fprintf(buf, "package %s\n", pkg)
@@ -38,13 +51,22 @@ func generateCode(pkg string, list []*method) string {
 //     return (int) (*env)->GetObjectRefType(env, obj);
 // }
 //
+// static inline jclass DefineClass(JNIEnv * env, char * name, jobject loader, void * buf, jsize len) {
+//     return (*env)->DefineClass(env, name, loader, (const jbyte *) buf, len);
+// }
+//
+// static inline jint RegisterNatives(JNIEnv * env, jclass clazz, JNINativeMethod * methods, jint nMethods) {
+//     return (*env)->RegisterNatives(env, clazz, methods, nMethods);
+// }
+//
 `)
 
 	for _, m := range list {
-		if skip, err := generateFuncCode(m, buf); err == nil {
-			if !skip {
-				fmt.Fprintln(buf, "//")
-			}
+		skip, err := generateFuncCode(m, buf)
+		if err != nil {
+			errs = append(errs, err)
+		} else if !skip {
+			fmt.Fprintln(buf, "//")
 		}
 	}
 
@@ -60,8 +82,11 @@ const (
 	JNI_VERSION_1_4 = 0x00010004
 	JNI_VERSION_1_6 = 0x00010006
 	JNI_VERSION_1_8 = 0x00010008
-	JNI_VERSION_9	= 0x00090000
-	JNI_VERSION_10	= 0x000a0000
+	JNI_VERSION_9   = 0x00090000
+	JNI_VERSION_10  = 0x000a0000
+	JNI_VERSION_19  = 0x00130000
+	JNI_VERSION_20  = 0x00140000
+	JNI_VERSION_21  = 0x00150000
 
 	JNI_FALSE = 0
 	JNI_TRUE  = 1
@@ -139,6 +164,37 @@ func (env Env) GetObjectRefType(obj Jobject) RefType {
 	return RefType(C.GetObjectRefType((*C.JNIEnv)(unsafe.Pointer(env)), C.jobject(obj)))
 }
 
+func (env Env) DefineClass(name string, loader Jobject, buf []byte) Jclass {
+	cstr_name := C.CString(name)
+	defer C.free(unsafe.Pointer(cstr_name))
+	return Jclass(C.DefineClass((*C.JNIEnv)(unsafe.Pointer(env)), cstr_name, C.jobject(loader), ByteSlicePtr(buf), C.jsize(len(buf))))
+}
+
+// NativeMethod 对应 C 的 JNINativeMethod，FnPtr 必须是 C 函数指针（如 cgo 导出的 Go 函数）
+type NativeMethod struct {
+	Name      string
+	Signature string
+	FnPtr     unsafe.Pointer
+}
+
+func (env Env) RegisterNatives(clazz Jclass, methods []NativeMethod) int {
+	size := C.size_t(unsafe.Sizeof(C.JNINativeMethod{})) * C.size_t(len(methods))
+	cmethods := (*C.JNINativeMethod)(C.malloc(size))
+	defer C.free(unsafe.Pointer(cmethods))
+
+	table := unsafe.Slice(cmethods, len(methods))
+	for i, m := range methods {
+		cstr_name := C.CString(m.Name)
+		defer C.free(unsafe.Pointer(cstr_name))
+		cstr_sig := C.CString(m.Signature)
+		defer C.free(unsafe.Pointer(cstr_sig))
+		table[i].name = cstr_name
+		table[i].signature = cstr_sig
+		table[i].fnPtr = m.FnPtr
+	}
+	return int(C.RegisterNatives((*C.JNIEnv)(unsafe.Pointer(env)), C.jclass(clazz), cmethods, C.jint(len(methods))))
+}
+
 func (env Env) NewString(s string) Jstring {
 	codes := utf16.Encode([]rune(s))
 	size := len(codes)
@@ -260,39 +316,41 @@ func (env Env) SetDoubleArrayElement(array JdoubleArray, index int, v float64) {
 `)
 
 	for _, m := range list {
-		if skip, err := generateGoFuncCode(m, buf); err == nil {
-			if !skip {
-				fmt.Fprintln(buf)
-			}
+		skip, err := generateGoFuncCode(m, buf)
+		if err != nil {
+			errs = append(errs, err)
+		} else if !skip {
+			fmt.Fprintln(buf)
 		}
 	}
 
 	fmt.Fprint(buf, `func DoubleValue(f float64) Jvalue {
-	return *(*Jvalue)(unsafe.Pointer(&f))
+	return ValueOf(f)
 }
 
 func FloatValue(f float32) Jvalue {
-	return Jvalue(*(*uint32)(unsafe.Pointer(&f)))
+	return ValueOf(f)
 }
 
 func Int8Value(i int8) Jvalue {
-	return Jvalue(*(*uint8)(unsafe.Pointer(&i)))
+	return ValueOf(i)
 }
 
 func Int16Value(i int16) Jvalue {
-	return Jvalue(*(*uint16)(unsafe.Pointer(&i)))
+	return ValueOf(i)
 }
 
 func Int32Value(i int32) Jvalue {
-	return Jvalue(*(*uint32)(unsafe.Pointer(&i)))
+	return ValueOf(i)
 }
 
+// IntValue 把 i 作为 jint 传递，在 32 位和 64 位平台上都只保留低 32 位
 func IntValue(i int) Jvalue {
-	return Jvalue(*(*uint)(unsafe.Pointer(&i)))
+	return ValueOf(i)
 }
 
 func BooleanValue(b bool) Jvalue {
-	return Jvalue(cbool(b))
+	return ValueOf(b)
 }
 
 func Bool(b uint8) bool {
@@ -363,13 +421,16 @@ func cDoubleArray(a []float64) *C.jdouble {
 }
 `)
 
-	return buf.String()
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	return buf.String(), nil
 }
 
 // 处理参数，去掉参数中的 const
 // 处理返回值，去掉返回值中的 const
 func generateFuncCode(m *method, buf *bytes.Buffer) (skip bool, err error) {
-	if m.isVarArgs() || m.isVaList() {
+	if m.isVariadic() {
 		return false, fmt.Errorf("%s 处理不了不定参数的情况", m)
 	}
 
@@ -392,16 +453,28 @@ func generateFuncCode(m *method, buf *bytes.Buffer) (skip bool, err error) {
 
 	expr = m.toC().beforeReturn(expr)
 
+	// 旧版本的 jni.h 中没有这个函数指针，编译时需要判断
+	since, versioned := sinceVersion(m.name)
+	if versioned {
+		fmt.Fprintf(buf, "// #ifdef %s\n", since)
+	}
 	fmt.Fprintf(buf, "//     %s%s;\n",
 		ret,
 		expr)
+	if versioned {
+		fmt.Fprint(buf, "// #else\n")
+		if m.hasRetVal() {
+			fmt.Fprint(buf, "//     return 0;\n")
+		}
+		fmt.Fprint(buf, "// #endif\n")
+	}
 	fmt.Fprint(buf, "// }\n")
 
 	return false, nil
 }
 
 func generateGoFuncCode(m *method, buf *bytes.Buffer) (bool, error) {
-	if m.isVarArgs() || m.isVaList() {
+	if m.isVariadic() {
 		return false, fmt.Errorf("%s 处理不了不定参数的情况", m)
 	}
 
@@ -410,6 +483,11 @@ func generateGoFuncCode(m *method, buf *bytes.Buffer) (bool, error) {
 		return true, nil
 	}
 
+	since, versioned := sinceVersion(m.name)
+	if versioned {
+		return false, generateGoVersionedFuncCode(m, since, buf)
+	}
+
 	fmt.Fprintf(buf, "func (%s %s) %s(%s)%s {\n",
 		m.params[0].idName, m.params[0].cType.toGo().TypeDesc(),
 		m.name, m.toGo().paramList(), m.goRetVal())
@@ -433,3 +511,46 @@ func generateGoFuncCode(m *method, buf *bytes.Buffer) (bool, error) {
 
 	return false, nil
 }
+
+// 生成需要版本检查的函数：JVM 版本不够时返回 ErrUnsupported，而不是调用空的函数指针
+func generateGoVersionedFuncCode(m *method, since string, buf *bytes.Buffer) error {
+	self := m.params[0].idName
+	if m.params[0].typeName != "JNIEnv" {
+		return fmt.Errorf("%s 只有 JNIEnv 的函数可以检查版本", m)
+	}
+
+	if m.hasRetVal() {
+		fmt.Fprintf(buf, "func (%s %s) %s(%s) (%s, error) {\n",
+			self, m.params[0].cType.toGo().TypeDesc(),
+			m.name, m.toGo().paramList(), m.ret.toGo().TypeDesc())
+	} else {
+		fmt.Fprintf(buf, "func (%s %s) %s(%s) error {\n",
+			self, m.params[0].cType.toGo().TypeDesc(),
+			m.name, m.toGo().paramList())
+	}
+
+	fmt.Fprintf(buf, "\tif err := %s.requireVersion(%s, %q); err != nil {\n", self, since, m.name)
+	if m.hasRetVal() {
+		fmt.Fprintf(buf, "\t\treturn %s, err\n", m.ret.toGo().zeroValue())
+	} else {
+		fmt.Fprint(buf, "\t\treturn err\n")
+	}
+	fmt.Fprint(buf, "\t}\n")
+
+	m.toGo().prepareReturn(buf)
+
+	expr := fmt.Sprintf("C.%s(%s)",
+		m.name,
+		m.toGo().callList())
+	expr = m.toGo().beforeReturn(expr)
+
+	if m.hasRetVal() {
+		fmt.Fprintf(buf, "\treturn %s, nil\n", expr)
+	} else {
+		fmt.Fprintf(buf, "\t%s\n", expr)
+		fmt.Fprint(buf, "\treturn nil\n")
+	}
+	fmt.Fprint(buf, "}\n")
+
+	return nil
+}
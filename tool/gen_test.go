@@ -0,0 +1,50 @@
+package tool
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCode(t *testing.T) {
+	h, err := ParseHeader("jni.h", []byte(sampleHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := h.VarArgs(), []string{"NewObject", "NewObjectV"}; !slices.Equal(got, want) {
+		t.Errorf("不定参数的函数为 %q，期望 %q", got, want)
+	}
+
+	code, err := GenerateCode("jni", h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"func (env Env) FindClass(name string) Jclass {",
+		"func (env Env) IsVirtualThread(obj Jobject) (bool, error) {",
+		"// static inline jint DestroyJavaVM(JavaVM * vm) {",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("生成的代码中没有 %q", s)
+		}
+	}
+	if strings.Contains(code, "NewObjectV") {
+		t.Error("生成的代码中不应该有不定参数的函数 NewObjectV")
+	}
+}
+
+func TestGenerateCodeError(t *testing.T) {
+	// 需要版本检查的函数只能属于 JNIEnv
+	src := strings.Replace(sampleHeader, "jint (JNICALL *DestroyJavaVM)(JavaVM *vm);",
+		"jint (JNICALL *DestroyJavaVM)(JavaVM *vm);\n    jboolean (JNICALL *IsVirtualThread)(JavaVM *vm, jobject obj);", 1)
+	h, err := ParseHeader("jni.h", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = GenerateCode("jni", h)
+	if err == nil || !strings.Contains(err.Error(), "只有 JNIEnv 的函数可以检查版本") {
+		t.Errorf("错误为 %v，期望版本检查的错误", err)
+	}
+}
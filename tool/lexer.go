@@ -0,0 +1,171 @@
+package tool
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pos 表示源码中的位置，行号和列号都从 1 开始
+type Pos struct {
+	Line int
+	Col  int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Col)
+}
+
+// SyntaxError 表示解析 C 声明时遇到的错误，File 为空时表示解析的是字符串
+type SyntaxError struct {
+	File string
+	Pos  Pos
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.File != "" {
+		return e.File + ":" + e.Pos.String() + ": " + e.Msg
+	}
+	return e.Pos.String() + ": " + e.Msg
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokLiteral // 字符串或字符字面量
+	tokPunct
+	tokEllipsis
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  Pos
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "文件结尾"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// 把 C 代码切分为 token，跳过空白、注释和预处理指令。
+// 声明中用不到的符号（如 C++ 部分的 -> 和 &）也会作为单个字符的 tokPunct 返回，由解析器报告错误。
+func tokenize(file, code string) ([]token, error) {
+	var toks []token
+	pos := Pos{Line: 1, Col: 1}
+	lineStart := true
+
+	advance := func(n int) {
+		for _, c := range code[:n] {
+			if c == '\n' {
+				pos.Line++
+				pos.Col = 1
+			} else {
+				pos.Col++
+			}
+		}
+		code = code[n:]
+	}
+	fail := func(msg string) error {
+		return &SyntaxError{File: file, Pos: pos, Msg: msg}
+	}
+
+	for len(code) > 0 {
+		c := code[0]
+		switch {
+		case c == '\n':
+			advance(1)
+			lineStart = true
+			continue
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			advance(1)
+			continue
+
+		case strings.HasPrefix(code, "//"):
+			n := strings.IndexByte(code, '\n')
+			if n < 0 {
+				n = len(code)
+			}
+			advance(n)
+			continue
+
+		case strings.HasPrefix(code, "/*"):
+			n := strings.Index(code[2:], "*/")
+			if n < 0 {
+				return nil, fail("注释没有结束")
+			}
+			advance(n + 4)
+			continue
+
+		case c == '#' && lineStart:
+			// 预处理指令，行尾的 \ 表示下一行也属于这条指令
+			n := 0
+			for n < len(code) && (code[n] != '\n' || n > 0 && code[n-1] == '\\') {
+				n++
+			}
+			advance(n)
+			continue
+
+		case strings.HasPrefix(code, "..."):
+			toks = append(toks, token{kind: tokEllipsis, text: "...", pos: pos})
+			advance(3)
+
+		case c == '"' || c == '\'':
+			n := 1
+			for n < len(code) && code[n] != c && code[n] != '\n' {
+				if code[n] == '\\' {
+					n++
+				}
+				n++
+			}
+			if n >= len(code) || code[n] != c {
+				return nil, fail("字面量没有结束")
+			}
+			toks = append(toks, token{kind: tokLiteral, text: code[:n+1], pos: pos})
+			advance(n + 1)
+
+		case isIdentByte(c) && !isDigit(c):
+			n := identLen(code)
+			toks = append(toks, token{kind: tokIdent, text: code[:n], pos: pos})
+			advance(n)
+
+		case isDigit(c):
+			// 包括 0x10、16L 等形式
+			n := identLen(code)
+			toks = append(toks, token{kind: tokNumber, text: code[:n], pos: pos})
+			advance(n)
+
+		case c < 0x80:
+			toks = append(toks, token{kind: tokPunct, text: code[:1], pos: pos})
+			advance(1)
+
+		default:
+			return nil, fail(fmt.Sprintf("无法识别的字符 %q", c))
+		}
+		lineStart = false
+	}
+
+	return append(toks, token{kind: tokEOF, pos: pos}), nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || isDigit(c) || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func identLen(s string) int {
+	n := 0
+	for n < len(s) && isIdentByte(s[n]) {
+		n++
+	}
+	return n
+}
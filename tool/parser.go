@@ -1,164 +1,473 @@
 package tool
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"os"
-	"regexp"
 	"strings"
-	"unicode"
 )
 
-var identifier = regexp.MustCompile(`^\w+`)
-var constr = []byte("const")
-var methodPtr = regexp.MustCompile(`^\(JNICALL\s+\*(\w+)\)`)
+// C 的基本类型关键字，可以组合使用，如 unsigned long
+var baseTypes = map[string]bool{
+	"void":     true,
+	"char":     true,
+	"short":    true,
+	"int":      true,
+	"long":     true,
+	"float":    true,
+	"double":   true,
+	"signed":   true,
+	"unsigned": true,
+}
+
+// 不影响类型的关键字和宏，解析时直接跳过
+var ignoredIdents = map[string]bool{
+	"JNICALL":   true,
+	"JNIEXPORT": true,
+	"JNIIMPORT": true,
+	"extern":    true,
+	"static":    true,
+	"volatile":  true,
+	"register":  true,
+}
+
+// 表示一个 C 声明，如结构体成员或函数参数
+type decl struct {
+	pos  Pos
+	name string
+	typ  cType
+}
+
+// 表示 C 语言的结构体
+type cStruct struct {
+	name   string
+	fields []*decl
+}
 
-func parseIdentifier(code []byte) (id *cType, next []byte, err error) {
-	if code[0] == ',' || unicode.IsSpace(rune(code[0])) {
-		next = code[1:]
-		next = bytes.TrimLeftFunc(next, unicode.IsSpace)
-	} else {
-		next = code
+type parser struct {
+	file string
+	toks []token
+	i    int
+}
+
+func newParser(file, code string) (*parser, error) {
+	toks, err := tokenize(file, code)
+	if err != nil {
+		return nil, err
 	}
+	return &parser{file: file, toks: toks}, nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
 
-	// 判断是否是 varArgs
-	if next[0] == '.' && next[1] == '.' && next[2] == '.' {
-		id = &cType{isVarArgs: true}
-		next = bytes.TrimLeftFunc(next[3:], unicode.IsSpace)
-		return
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
 	}
+	return t
+}
+
+// 当前 token 是否是指定的符号或关键字
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return t.kind != tokEOF && t.text == text
+}
 
-	loc := identifier.FindIndex(next)
-	if len(loc) == 0 {
-		err = fmt.Errorf("%q 找不到标识符1", code)
-		return
+func (p *parser) expect(text string) (token, error) {
+	if !p.is(text) {
+		return token{}, p.errorf(p.peek(), "期望 %q，实际是 %s", text, p.peek())
 	}
+	return p.next(), nil
+}
 
-	id = new(cType)
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return &SyntaxError{File: p.file, Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
 
-	// 判断是否以 const 开头
-	if bytes.Equal(next[loc[0]:loc[1]], constr) {
-		id.isConst = true
-		next = bytes.TrimLeftFunc(next[loc[1]:], unicode.IsSpace)
+func (p *parser) skipIgnored() {
+	for p.peek().kind == tokIdent && ignoredIdents[p.peek().text] {
+		p.next()
+	}
+}
 
-		if next[0] == '*' {
-			err = fmt.Errorf("%q 处理不了 const * xxx 的情况", code)
-			id = nil
+// 出错后跳到下一个分号之后继续解析，遇到结构体结尾时停止
+func (p *parser) recover() {
+	for !p.is("}") {
+		if t := p.next(); t.kind == tokEOF || t.text == ";" {
 			return
 		}
+	}
+}
 
-		loc = identifier.FindIndex(next)
-		if len(loc) == 0 {
-			err = fmt.Errorf("%q 找不到标识符2", code)
-			return
-		}
+// 解析单独的结构体定义，支持 struct Name { ... }; 和 typedef struct { ... } Name; 两种形式
+func parseStruct(code string) (*cStruct, error) {
+	p, err := newParser("", code)
+	if err != nil {
+		return nil, err
 	}
 
-	id.typeName = string(next[loc[0]:loc[1]])
-	next = bytes.TrimLeftFunc(next[loc[1]:], unicode.IsSpace)
-	if next[0] == '*' {
-		id.isPtr = true
-		next = bytes.TrimLeftFunc(next[1:], unicode.IsSpace)
+	s, err := p.parseStruct()
+	if err != nil {
+		return nil, err
 	}
-	if next[0] == '*' {
-		id = nil
-		err = fmt.Errorf("%q 处理不了多重指针的情况", code)
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "结构体定义之后多余的 %s", t)
 	}
+	return s, nil
+}
 
-	return
+// 在整个文件中查找 struct name { ... } 的定义，并把当前位置移到定义的开头
+func (p *parser) findStruct(name string) bool {
+	for i := 0; i+2 < len(p.toks); i++ {
+		if p.toks[i].text == "struct" && p.toks[i+1].text == name && p.toks[i+2].text == "{" {
+			p.i = i
+			return true
+		}
+	}
+	return false
 }
 
-func parseFuncPointerName(code []byte) (name string, next []byte, err error) {
-	loc := methodPtr.FindSubmatchIndex(code)
-	if len(loc) == 0 {
-		err = fmt.Errorf("%q 找不到函数指针名称", code)
-		return
-	} else if len(loc) < 4 {
-		err = fmt.Errorf("%q 找不到函数指针名称分组", code)
-	} else {
-		name = string(code[loc[2]:loc[3]])
+// 在整个文件中查找 typedef struct { ... } name; 的定义，并把当前位置移到定义的开头
+func (p *parser) findTypedefStruct(name string) bool {
+	for i := 1; i+1 < len(p.toks); i++ {
+		if p.toks[i].text != name || p.toks[i-1].text != "}" || p.toks[i+1].text != ";" {
+			continue
+		}
+
+		// 向前找到匹配的 {
+		depth := 0
+		for j := i - 1; j >= 0; j-- {
+			switch p.toks[j].text {
+			case "}":
+				depth++
+			case "{":
+				depth--
+			}
+			if depth > 0 {
+				continue
+			}
+
+			// typedef struct [tag] {
+			k := j - 1
+			if k >= 0 && p.toks[k].kind == tokIdent && p.toks[k].text != "struct" {
+				k--
+			}
+			if k >= 1 && p.toks[k].text == "struct" && p.toks[k-1].text == "typedef" {
+				p.i = k - 1
+				return true
+			}
+			break
+		}
 	}
-	next = bytes.TrimLeftFunc(code[loc[1]:], unicode.IsSpace)
-	return
+	return false
 }
 
-func parseFuncParamList(code []byte) (params []*param, next []byte, err error) {
-	if code[0] == '(' {
-		next = code[1:]
-	} else {
-		next = code
+// 解析从当前位置开始的结构体定义。出错的成员会被跳过，所有错误通过 errors.Join 一起返回。
+func (p *parser) parseStruct() (*cStruct, error) {
+	start := p.peek()
+	typedef := p.is("typedef")
+	if typedef {
+		p.next()
+	}
+	if _, err := p.expect("struct"); err != nil {
+		return nil, err
 	}
 
-	var t *cType
-	var loc []int
+	s := new(cStruct)
+	if p.peek().kind == tokIdent {
+		s.name = p.next().text
+	}
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
 
-	for next[0] != ')' {
-		if t, next, err = parseIdentifier(next); err != nil {
-			return
+	var errs []error
+	for !p.is("}") {
+		if p.peek().kind == tokEOF {
+			return nil, p.errorf(start, "struct %s 没有结束", s.name)
+		}
+		ds, err := p.parseDecl()
+		if err != nil {
+			errs = append(errs, err)
+			p.recover()
+			continue
 		}
+		s.fields = append(s.fields, ds...)
+	}
+	p.next()
 
-		loc = identifier.FindIndex(next)
-		// varargs 的情况
-		if len(loc) == 0 {
-			if t.isVarArgs && next[0] == ')' {
-				params = append(params, &param{cType: *t})
-			} else {
-				err = fmt.Errorf("%q 解析时出现异常", next)
-			}
+	if typedef {
+		t := p.next()
+		if t.kind != tokIdent {
+			return nil, p.errorf(t, "typedef 缺少类型名称")
+		}
+		s.name = t.text
+	}
+	if s.name == "" {
+		return nil, p.errorf(start, "结构体缺少名称")
+	}
+	if _, err := p.expect(";"); err != nil {
+		errs = append(errs, err)
+	}
+	return s, errors.Join(errs...)
+}
+
+// 解析一条以分号结尾的声明
+func (p *parser) parseDecl() ([]*decl, error) {
+	base, err := p.parseSpecifiers()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*decl
+	for {
+		d, err := p.parseDeclarator(base, false)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
 
+		if !p.is(",") {
 			break
 		}
+		p.next()
+	}
 
-		idName := string(next[loc[0]:loc[1]])
-		// 避免形参名称和 Go 保留字冲突
-		if idName == "string" {
-			idName = "str"
+	if _, err = p.expect(";"); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// 解析类型名和 const，const 可以出现在类型名的前面或后面
+func (p *parser) parseSpecifiers() (cType, error) {
+	var t cType
+	var words []string
+	named := false
+
+loop:
+	for p.peek().kind == tokIdent {
+		tok := p.peek()
+		switch {
+		case tok.text == "const":
+			t.isConst = true
+			p.next()
+
+		case ignoredIdents[tok.text]:
+			p.next()
+
+		case named:
+			// 类型名之后的标识符是声明的名称
+			break loop
+
+		case tok.text == "struct" || tok.text == "union" || tok.text == "enum":
+			if len(words) > 0 {
+				return t, p.errorf(tok, "%s 之前不能有其他类型名", tok.text)
+			}
+			p.next()
+			name := p.next()
+			if name.kind != tokIdent {
+				return t, p.errorf(name, "%s 缺少名称", tok.text)
+			}
+			if p.is("{") {
+				return t, p.errorf(p.peek(), "不支持在声明中定义 %s", tok.text)
+			}
+			words = append(words, tok.text+" "+name.text)
+			named = true
+
+		case baseTypes[tok.text]:
+			words = append(words, tok.text)
+			p.next()
+
+		default:
+			if len(words) > 0 {
+				break loop
+			}
+			// typedef 定义的类型，如 jint、JNIEnv
+			words = append(words, tok.text)
+			named = true
+			p.next()
+		}
+	}
+
+	if len(words) == 0 {
+		return t, p.errorf(p.peek(), "期望类型名，实际是 %s", p.peek())
+	}
+	t.typeName = strings.Join(words, " ")
+	return t, nil
+}
+
+// 解析指针，每一级指针后面都可以有 const
+func (p *parser) parsePointers() []bool {
+	var ptrs []bool
+	for p.is("*") {
+		p.next()
+		isConst := false
+		for p.peek().kind == tokIdent && (p.peek().text == "const" || ignoredIdents[p.peek().text]) {
+			if p.next().text == "const" {
+				isConst = true
+			}
+		}
+		ptrs = append(ptrs, isConst)
+	}
+	return ptrs
+}
+
+// 解析声明符：指针、名称、数组维度，或者 ret (JNICALL *name)(params) 形式的函数指针。
+// abstract 为 true 时允许省略名称（函数参数）。
+func (p *parser) parseDeclarator(base cType, abstract bool) (*decl, error) {
+	t := base
+	t.ptrs = p.parsePointers()
+
+	if p.is("(") {
+		d, ptrs, err := p.parseFuncPtr(abstract)
+		if err != nil {
+			return nil, err
+		}
+
+		params, err := p.parseParams()
+		if err != nil {
+			return nil, err
+		}
+		d.typ = cType{ptrs: ptrs, fn: &funcType{ret: t, params: params}}
+		return d, nil
+	}
+
+	d, err := p.parseName(abstract)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.is("[") {
+		p.next()
+		dim := ""
+		if p.peek().kind == tokNumber {
+			dim = p.next().text
 		}
-		params = append(params, &param{cType: *t, idName: idName})
-		next = bytes.TrimLeftFunc(next[loc[1]:], unicode.IsSpace)
+		if _, err = p.expect("]"); err != nil {
+			return nil, err
+		}
+		t.dims = append(t.dims, dim)
 	}
 
-	return
+	if p.is("(") {
+		return nil, p.errorf(p.peek(), "不支持函数声明，只支持函数指针")
+	}
+
+	d.typ = t
+	return d, nil
 }
 
-func parseSingleMethod(code []byte) (m *method, err error) {
-	// 找返回值
-	var ret *cType
-	if ret, code, err = parseIdentifier(code); err != nil {
-		return
+// 解析函数指针括号中的部分，如 (JNICALL *name) 或 (*(*name))，返回名称和指向函数的指针层数
+func (p *parser) parseFuncPtr(abstract bool) (*decl, []bool, error) {
+	open := p.next()
+	p.skipIgnored()
+	if !p.is("*") {
+		return nil, nil, p.errorf(open, "括号中只支持函数指针")
 	}
+	ptrs := p.parsePointers()
 
-	// 找函数指针名称
-	var name string
-	if name, code, err = parseFuncPointerName(code); err != nil {
-		return
+	var d *decl
+	var err error
+	if p.is("(") {
+		// 内层的指针更靠近名称
+		var inner []bool
+		if d, inner, err = p.parseFuncPtr(abstract); err != nil {
+			return nil, nil, err
+		}
+		ptrs = append(ptrs, inner...)
+	} else if d, err = p.parseName(abstract); err != nil {
+		return nil, nil, err
 	}
 
-	// 找函数参数列表
-	var params []*param
-	if params, code, err = parseFuncParamList(code); err != nil {
-		return
+	switch {
+	case p.is("["):
+		return nil, nil, p.errorf(p.peek(), "不支持函数指针数组")
+	case p.is("("):
+		return nil, nil, p.errorf(p.peek(), "不支持返回函数指针的函数")
+	}
+	if _, err = p.expect(")"); err != nil {
+		return nil, nil, err
 	}
+	return d, ptrs, nil
+}
 
-	return &method{ret: ret, name: name, params: params}, nil
+func (p *parser) parseName(abstract bool) (*decl, error) {
+	t := p.peek()
+	d := &decl{pos: t.pos}
+	if t.kind == tokIdent && !baseTypes[t.text] && t.text != "const" {
+		d.name = p.next().text
+	} else if !abstract {
+		return nil, p.errorf(t, "期望名称，实际是 %s", t)
+	}
+	return d, nil
 }
 
-func parseJniMethodList(code string) (list []*method) {
-	lines := strings.Split(code, ";")
+// 解析函数参数列表，(void) 表示没有参数，... 必须是最后一个参数
+func (p *parser) parseParams() ([]*decl, error) {
+	if _, err := p.expect("("); err != nil {
+		return nil, err
+	}
 
-	for i := range lines {
-		lines[i] = strings.TrimSpace(lines[i])
+	if p.is("void") && p.toks[p.i+1].text == ")" {
+		p.next()
+	}
+
+	var params []*decl
+	for !p.is(")") {
+		if len(params) > 0 {
+			if _, err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
 
-		if len(lines[i]) > 0 {
-			if m, err := parseSingleMethod([]byte(lines[i])); err != nil {
-				fmt.Fprintf(os.Stderr, "// // 匹配 %q\n", lines[i])
-				fmt.Fprintln(os.Stderr, "// //", err)
-			} else {
-				//fmt.Printf("%s\n", m)
-				list = append(list, m)
+		if t := p.peek(); t.kind == tokEllipsis {
+			p.next()
+			params = append(params, &decl{pos: t.pos, typ: cType{isVarArgs: true}})
+			if !p.is(")") {
+				return nil, p.errorf(p.peek(), "... 必须是最后一个参数")
 			}
+			break
+		}
+
+		base, err := p.parseSpecifiers()
+		if err != nil {
+			return nil, err
+		}
+		d, err := p.parseDeclarator(base, true)
+		if err != nil {
+			return nil, err
 		}
+		// 数组参数等同于指针
+		if len(d.typ.dims) > 0 {
+			d.typ.dims = d.typ.dims[1:]
+			d.typ.ptrs = append(d.typ.ptrs, false)
+		}
+		params = append(params, d)
+	}
+	p.next()
+
+	return params, nil
+}
+
+// 把函数指针成员转换为 method，其他成员返回 nil
+func (d *decl) toMethod() *method {
+	fn := d.typ.fn
+	if fn == nil || len(d.typ.ptrs) != 1 {
+		return nil
 	}
 
-	return
+	ret := fn.ret
+	m := &method{name: d.name, ret: &ret}
+	for _, p := range fn.params {
+		idName := p.name
+		// 避免形参名称和 Go 保留字冲突
+		if idName == "string" {
+			idName = "str"
+		}
+		m.params = append(m.params, &param{cType: p.typ, idName: idName})
+	}
+	return m
 }
@@ -0,0 +1,338 @@
+package tool
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// 把类型格式化为便于比较的字符串，函数类型写作 func(参数) 返回值，后面跟函数指针的层数
+func formatType(c cType) string {
+	if c.isVarArgs {
+		return "..."
+	}
+
+	var s string
+	if c.fn != nil {
+		var params []string
+		for _, p := range c.fn.params {
+			params = append(params, formatType(p.typ))
+		}
+		s = "func(" + strings.Join(params, ", ") + ") " + formatType(c.fn.ret)
+	} else {
+		if c.isConst {
+			s = "const "
+		}
+		s += c.typeName
+	}
+
+	for _, isConst := range c.ptrs {
+		s += " *"
+		if isConst {
+			s += " const"
+		}
+	}
+	for _, dim := range c.dims {
+		s += "[" + dim + "]"
+	}
+	return s
+}
+
+func parseField(t *testing.T, code string) *decl {
+	t.Helper()
+	s, err := parseStruct("struct T { " + code + " };")
+	if err != nil {
+		t.Fatalf("%q: %v", code, err)
+	}
+	if len(s.fields) != 1 {
+		t.Fatalf("%q: 解析出 %d 个成员", code, len(s.fields))
+	}
+	return s.fields[0]
+}
+
+func TestParseDeclaration(t *testing.T) {
+	tests := []struct {
+		code string
+		name string
+		want string
+	}{
+		{"void **penv;", "penv", "void * *"},
+		{"JNIEnv **p_env;", "p_env", "JNIEnv * *"},
+		{"const char *name;", "name", "const char *"},
+		{"char const *name;", "name", "const char *"},
+		{"char * const name;", "name", "char * const"},
+		{"const char * const * const names;", "names", "const char * const * const"},
+		{"const jint len;", "len", "const jint"},
+		{"char buf[16];", "buf", "char[16]"},
+		{"char *argv[];", "argv", "char *[]"},
+		{"int m[2][3];", "m", "int[2][3]"},
+		{"unsigned long long n;", "n", "unsigned long long"},
+		{"struct JNINativeInterface_ *functions;", "functions", "struct JNINativeInterface_ *"},
+		{"jint (JNICALL *GetVersion)(JNIEnv *env);", "GetVersion", "func(JNIEnv *) jint *"},
+		{"jint (JNICALL *DestroyJavaVM)(void);", "DestroyJavaVM", "func() jint *"},
+		{"void* (JNICALL *GetDirectBufferAddress)(JNIEnv* env, jobject buf);", "GetDirectBufferAddress", "func(JNIEnv *, jobject) void * *"},
+		{"jint (JNICALL *GetEnv)(JavaVM *vm, void **penv, jint version);", "GetEnv", "func(JavaVM *, void * *, jint) jint *"},
+		{"jclass (JNICALL *FindClass)(JNIEnv *env, const char *name);", "FindClass", "func(JNIEnv *, const char *) jclass *"},
+		{"jobject (JNICALL *NewObject)(JNIEnv *env, jclass clazz, jmethodID methodID, ...);", "NewObject", "func(JNIEnv *, jclass, jmethodID, ...) jobject *"},
+		{"jobject (JNICALL *NewObjectV)(JNIEnv *env, jclass clazz, jmethodID methodID, va_list args);", "NewObjectV", "func(JNIEnv *, jclass, jmethodID, va_list) jobject *"},
+		{"void (*cb)(int a[], char *);", "cb", "func(int *, char *) void *"},
+		{"void (* const cb)(void (*)(int));", "cb", "func(func(int) void *) void * const"},
+		{"int (*(*fp))(void);", "fp", "func() int * *"},
+	}
+
+	for _, test := range tests {
+		d := parseField(t, test.code)
+		if d.name != test.name {
+			t.Errorf("%q: 名称为 %q，期望 %q", test.code, d.name, test.name)
+		}
+		if got := formatType(d.typ); got != test.want {
+			t.Errorf("%q: 类型为 %q，期望 %q", test.code, got, test.want)
+		}
+	}
+}
+
+func TestParseMultipleDeclarators(t *testing.T) {
+	s, err := parseStruct("struct T { void *reserved0, *reserved1, reserved2[4]; };")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, f := range s.fields {
+		got = append(got, f.name+" "+formatType(f.typ))
+	}
+	want := []string{"reserved0 void *", "reserved1 void *", "reserved2 void[4]"}
+	if !slices.Equal(got, want) {
+		t.Errorf("成员为 %q，期望 %q", got, want)
+	}
+}
+
+func TestToMethod(t *testing.T) {
+	tests := []struct {
+		code      string
+		isMethod  bool
+		params    []string
+		varArgs   bool
+		vaList    bool
+		retIsVoid bool
+	}{
+		{"jint (JNICALL *GetEnv)(JavaVM *vm, void **penv, jint version);", true, []string{"vm", "penv", "version"}, false, false, false},
+		{"jobject (JNICALL *NewObject)(JNIEnv *env, jclass clazz, jmethodID methodID, ...);", true, []string{"env", "clazz", "methodID", ""}, true, false, false},
+		{"jobject (JNICALL *NewObjectV)(JNIEnv *env, jclass clazz, jmethodID methodID, va_list args);", true, []string{"env", "clazz", "methodID", "args"}, false, true, false},
+		{"jstring (JNICALL *NewString)(JNIEnv *env, const jchar *string, jsize len);", true, []string{"env", "str", "len"}, false, false, false},
+		{"void (JNICALL *ExceptionClear)(JNIEnv *env);", true, []string{"env"}, false, false, true},
+		{"void *reserved0;", false, nil, false, false, false},
+		{"int (*(*fp))(void);", false, nil, false, false, false},
+	}
+
+	for _, test := range tests {
+		m := parseField(t, test.code).toMethod()
+		if (m != nil) != test.isMethod {
+			t.Errorf("%q: toMethod() = %v", test.code, m)
+			continue
+		}
+		if m == nil {
+			continue
+		}
+
+		var params []string
+		for _, p := range m.params {
+			params = append(params, p.idName)
+		}
+		if !slices.Equal(params, test.params) {
+			t.Errorf("%q: 参数为 %q，期望 %q", test.code, params, test.params)
+		}
+		if m.isVarArgs() != test.varArgs || m.isVaList() != test.vaList || m.ret.isVoid() != test.retIsVoid {
+			t.Errorf("%q: isVarArgs=%t isVaList=%t isVoid=%t", test.code, m.isVarArgs(), m.isVaList(), m.ret.isVoid())
+		}
+	}
+}
+
+func TestParseTypedefStruct(t *testing.T) {
+	s, err := parseStruct(`typedef struct {
+    char *name;
+    char *signature;
+    void *fnPtr;
+} JNINativeMethod;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.name != "JNINativeMethod" {
+		t.Errorf("名称为 %q", s.name)
+	}
+
+	var got []string
+	for _, f := range s.fields {
+		got = append(got, f.pos.String()+" "+f.name+" "+formatType(f.typ))
+	}
+	want := []string{"2:11 name char *", "3:11 signature char *", "4:11 fnPtr void *"}
+	if !slices.Equal(got, want) {
+		t.Errorf("成员为 %q，期望 %q", got, want)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	tests := []struct {
+		code string
+		want []string
+	}{
+		{"struct T {\n  jint (JNICALL *A)(JNIEnv *env,;\n};", []string{`2:33: 期望类型名，实际是 ";"`}},
+		{"struct T {\n  int (C)(void);\n};", []string{"2:7: 括号中只支持函数指针"}},
+		{"struct T {\n  int f(int);\n};", []string{"2:8: 不支持函数声明，只支持函数指针"}},
+		{"struct T {\n  int (*(*fp)(int))(void);\n};", []string{"2:14: 不支持返回函数指针的函数"}},
+		{"struct T {\n  int (*fp[2])(void);\n};", []string{"2:11: 不支持函数指针数组"}},
+		{"struct T {\n  jint x y;\n};", []string{`2:10: 期望 ";"，实际是 "y"`}},
+		{"struct T {\n  int (*f)(..., int);\n};", []string{`2:15: ... 必须是最后一个参数`}},
+		{"struct T {\n  const;\n  int a\n};", []string{
+			`2:8: 期望类型名，实际是 ";"`,
+			`4:1: 期望 ";"，实际是 "}"`,
+		}},
+		{"struct T {\n  int a; /* 没有结束", []string{"2:10: 注释没有结束"}},
+		{"struct T {\n  int a;\n", []string{"1:1: struct T 没有结束"}},
+		{"struct {\n  int a;\n};", []string{"1:1: 结构体缺少名称"}},
+		{"struct T { int a; }; int b;", []string{`1:22: 结构体定义之后多余的 "int"`}},
+	}
+
+	for _, test := range tests {
+		_, err := parseStruct(test.code)
+		if err == nil {
+			t.Errorf("%q: 没有返回错误", test.code)
+			continue
+		}
+
+		var got []string
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				got = append(got, e.Error())
+			}
+		} else {
+			got = []string{err.Error()}
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%q: 错误为 %q，期望 %q", test.code, got, test.want)
+		}
+
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("%q: 错误类型为 %T", test.code, err)
+		}
+	}
+}
+
+// 结构与 JDK 中的 jni.h 相同：注释、预处理指令、C++ 部分以及 reserved 成员
+const sampleHeader = `/*
+ * jni.h 的结构示例
+ */
+#ifndef _JAVASOFT_JNI_H_
+#define _JAVASOFT_JNI_H_
+
+#include <stdio.h>
+#include "jni_md.h"
+
+#define JNI_VERSION_21 0x00150000
+#define JNI_LONG_MACRO(x) \
+    ((x) + 1)
+
+typedef struct {
+    char *name;
+    char *signature;
+    void *fnPtr;
+} JNINativeMethod;
+
+struct JNINativeInterface_;
+struct JNIEnv_;
+
+#ifdef __cplusplus
+typedef JNIEnv_ JNIEnv;
+#else
+typedef const struct JNINativeInterface_ *JNIEnv;
+#endif
+
+struct JNINativeInterface_ {
+    void *reserved0;
+    void *reserved1;
+    void *reserved2;
+
+    void *reserved3;
+    jint (JNICALL *GetVersion)(JNIEnv *env);
+
+    jclass (JNICALL *FindClass)
+      (JNIEnv *env, const char *name);
+
+    jobject (JNICALL *NewObject)
+      (JNIEnv *env, jclass clazz, jmethodID methodID, ...);
+    jobject (JNICALL *NewObjectV)
+      (JNIEnv *env, jclass clazz, jmethodID methodID, va_list args);
+
+    /* Virtual threads */
+
+    jboolean (JNICALL *IsVirtualThread)
+       (JNIEnv* env, jobject obj);
+};
+
+struct JNIEnv_ {
+    const struct JNINativeInterface_ *functions;
+#ifdef __cplusplus
+    jint GetVersion() {
+        return functions->GetVersion(this);
+    }
+    jint RegisterNatives(jclass clazz, const JNINativeMethod *methods, jint nMethods) {
+        return functions->RegisterNatives(this, clazz, methods, nMethods);
+    }
+#endif
+};
+
+struct JNIInvokeInterface_ {
+    void *reserved0;
+    void *reserved1;
+    void *reserved2;
+
+    jint (JNICALL *DestroyJavaVM)(JavaVM *vm);
+
+    jint (JNICALL *AttachCurrentThread)(JavaVM *vm, void **penv, void *args);
+
+    jint (JNICALL *GetEnv)(JavaVM *vm, void **penv, jint version);
+};
+
+#endif /* !_JAVASOFT_JNI_H_ */
+`
+
+func TestParseHeader(t *testing.T) {
+	h, err := ParseHeader("jni.h", []byte(sampleHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"DestroyJavaVM", "AttachCurrentThread", "GetEnv",
+		"GetVersion", "FindClass", "NewObject", "NewObjectV", "IsVirtualThread",
+	}
+	if got := h.Functions(); !slices.Equal(got, want) {
+		t.Errorf("函数为 %q，期望 %q", got, want)
+	}
+}
+
+func TestParseHeaderError(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     string
+	}{
+		// 位置对应 jni.h 中的行列号
+		{"(JNIEnv *env, const char *name);", "(JNIEnv *env, const char *name;", `jni.h:38:37: 期望 ","，实际是 ";"`},
+		{"jint (JNICALL *GetEnv)", "jint (JNICALL GetEnv)", "jni.h:72:10: 括号中只支持函数指针"},
+		{"void *fnPtr;", "void fnPtr;", "jni.h:14:1: JNINativeMethod 中没有指针成员 fnPtr"},
+		{"struct JNIInvokeInterface_ {", "struct JNIInvokeInterface {", "jni.h: 找不到 struct JNIInvokeInterface_ 的定义"},
+	}
+
+	for _, test := range tests {
+		if !strings.Contains(sampleHeader, test.old) {
+			t.Fatalf("sampleHeader 中没有 %q", test.old)
+		}
+		src := strings.Replace(sampleHeader, test.old, test.new, 1)
+		_, err := ParseHeader("jni.h", []byte(src))
+		if err == nil || err.Error() != test.want {
+			t.Errorf("%q: 错误为 %v，期望 %q", test.new, err, test.want)
+		}
+	}
+}
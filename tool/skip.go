@@ -1,6 +1,12 @@
 package tool
 
 var skipList = []string{
+	// 虚拟机，参数是二级指针，在 gen.go 中手写
+	"AttachCurrentThread",
+	"AttachCurrentThreadAsDaemon",
+	"GetEnv",
+	"GetJavaVM",
+
 	// 类操作
 	"DefineClass",
 
@@ -34,7 +40,6 @@ var skipList = []string{
 
 	// 注册
 	"RegisterNatives",
-	"UnregisterNatives",
 
 	// 引用操作
 	"GetObjectRefType",
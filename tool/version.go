@@ -0,0 +1,16 @@
+package tool
+
+// JNI 9 之后才加入函数表的函数及其所需的最低版本。
+// 旧版本 JVM 的函数表中没有这些槽位，调用前必须先检查 GetVersion()。
+var sinceList = map[string]string{
+	// 模块
+	"GetModule": "JNI_VERSION_9",
+
+	// 虚拟线程
+	"IsVirtualThread": "JNI_VERSION_21",
+}
+
+func sinceVersion(name string) (version string, ok bool) {
+	version, ok = sinceList[name]
+	return
+}
@@ -0,0 +1,447 @@
+package jni
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ClarkGuan/jni/classfile"
+)
+
+// Field 是 Go 类型为 T 的实例字段，第一次使用时解析字段 ID，并检查 T 与字段描述符是否匹配。
+// T 的取值与 Marshal 相同：基本类型、string、Jobject、切片、结构体以及基本类型的指针（包装类）。
+//
+// Get 和 Set 在解析失败或值转换失败（如创建 Java 字符串或数组时抛出异常、结构体中有无法转换的字段）时 panic，
+// 可以在加载时调用 Resolve（或使用 Bind）提前检查解析错误，或者使用返回错误的 TryGet 和 TrySet。
+type Field[T any] struct {
+	memberHandle
+}
+
+// NewField 创建 className 中名为 name、描述符为 descriptor 的实例字段
+func NewField[T any](className, name, descriptor string) *Field[T] {
+	f := new(Field[T])
+	f.init(className, name, descriptor)
+	return f
+}
+
+// Resolve 解析字段 ID，成功后不再重复解析，失败时下次调用会重试
+func (f *Field[T]) Resolve(env Env) error {
+	return f.resolve(env, true, false, reflect.TypeFor[T]())
+}
+
+// Get 读取 obj 的字段值，引用类型的值为局部引用
+func (f *Field[T]) Get(env Env, obj Jobject) T {
+	return must(f.TryGet(env, obj))
+}
+
+// TryGet 与 Get 相同，失败时返回错误而不是 panic
+func (f *Field[T]) TryGet(env Env, obj Jobject) (T, error) {
+	if err := f.Resolve(env); err != nil {
+		var zero T
+		return zero, err
+	}
+	return getValue[T](env, &f.memberHandle, f.fieldValue(env, obj))
+}
+
+// Set 设置 obj 的字段值
+func (f *Field[T]) Set(env Env, obj Jobject, v T) {
+	must(struct{}{}, f.TrySet(env, obj, v))
+}
+
+// TrySet 与 Set 相同，失败时返回错误而不是 panic
+func (f *Field[T]) TrySet(env Env, obj Jobject, v T) error {
+	if err := f.Resolve(env); err != nil {
+		return err
+	}
+	return f.setFieldValue(env, obj, reflect.ValueOf(&v).Elem())
+}
+
+// StaticField 与 Field 相同，用于静态字段
+type StaticField[T any] struct {
+	memberHandle
+}
+
+// NewStaticField 创建 className 中名为 name、描述符为 descriptor 的静态字段
+func NewStaticField[T any](className, name, descriptor string) *StaticField[T] {
+	f := new(StaticField[T])
+	f.init(className, name, descriptor)
+	return f
+}
+
+// Resolve 解析字段 ID，成功后不再重复解析，失败时下次调用会重试
+func (f *StaticField[T]) Resolve(env Env) error {
+	return f.resolve(env, true, true, reflect.TypeFor[T]())
+}
+
+// Get 读取静态字段的值，引用类型的值为局部引用
+func (f *StaticField[T]) Get(env Env) T {
+	return must(f.TryGet(env))
+}
+
+// TryGet 与 Get 相同，失败时返回错误而不是 panic
+func (f *StaticField[T]) TryGet(env Env) (T, error) {
+	if err := f.Resolve(env); err != nil {
+		var zero T
+		return zero, err
+	}
+	return getValue[T](env, &f.memberHandle, f.fieldValue(env, 0))
+}
+
+// Set 设置静态字段的值
+func (f *StaticField[T]) Set(env Env, v T) {
+	must(struct{}{}, f.TrySet(env, v))
+}
+
+// TrySet 与 Set 相同，失败时返回错误而不是 panic
+func (f *StaticField[T]) TrySet(env Env, v T) error {
+	if err := f.Resolve(env); err != nil {
+		return err
+	}
+	return f.setFieldValue(env, 0, reflect.ValueOf(&v).Elem())
+}
+
+// Method 是返回值为 R 的实例方法，第一次使用时解析方法 ID，并检查 R 与返回值描述符是否匹配。
+// void 方法使用 Method[struct{}]。
+type Method[R any] struct {
+	memberHandle
+}
+
+// NewMethod 创建 className 中名为 name、描述符为 descriptor 的实例方法
+func NewMethod[R any](className, name, descriptor string) *Method[R] {
+	m := new(Method[R])
+	m.init(className, name, descriptor)
+	return m
+}
+
+// Resolve 解析方法 ID，成功后不再重复解析，失败时下次调用会重试
+func (m *Method[R]) Resolve(env Env) error {
+	return m.resolve(env, false, false, reflect.TypeFor[R]())
+}
+
+// Call 调用 obj 的方法，args 按照 Values 的规则转换。Java 抛出的异常以 *Exception 返回。
+func (m *Method[R]) Call(env Env, obj Jobject, args ...any) (R, error) {
+	var ret R
+	if err := m.Resolve(env); err != nil {
+		return ret, err
+	}
+	return ret, m.call(env, obj, args, reflect.ValueOf(&ret).Elem())
+}
+
+// StaticMethod 与 Method 相同，用于静态方法
+type StaticMethod[R any] struct {
+	memberHandle
+}
+
+// NewStaticMethod 创建 className 中名为 name、描述符为 descriptor 的静态方法
+func NewStaticMethod[R any](className, name, descriptor string) *StaticMethod[R] {
+	m := new(StaticMethod[R])
+	m.init(className, name, descriptor)
+	return m
+}
+
+// Resolve 解析方法 ID，成功后不再重复解析，失败时下次调用会重试
+func (m *StaticMethod[R]) Resolve(env Env) error {
+	return m.resolve(env, false, true, reflect.TypeFor[R]())
+}
+
+// Call 调用静态方法，args 按照 Values 的规则转换。Java 抛出的异常以 *Exception 返回。
+func (m *StaticMethod[R]) Call(env Env, args ...any) (R, error) {
+	var ret R
+	if err := m.Resolve(env); err != nil {
+		return ret, err
+	}
+	return ret, m.call(env, 0, args, reflect.ValueOf(&ret).Elem())
+}
+
+// 各种字段和方法共用的部分
+type memberHandle struct {
+	className string
+	name      string
+	sig       string
+
+	mu       sync.Mutex
+	resolved atomic.Bool
+	static   bool
+	class    Jclass // 全局引用，来自类缓存
+	id       uintptr
+	ret      string // 方法的返回值描述符
+}
+
+// 设置成员的类名、名称和描述符。已经解析的成员不能改为其他成员，否则 Resolve 仍然使用之前的 ID
+func (h *memberHandle) init(className, name, sig string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.resolved.Load() && (h.className != className || h.name != name || h.sig != sig) {
+		return fmt.Errorf("jni: %s.%s%s 已经解析，不能再绑定为 %s.%s%s", h.className, h.name, h.sig, className, name, sig)
+	}
+	h.className, h.name, h.sig = className, name, sig
+	return nil
+}
+
+// 只记住成功的结果：失败可能是暂时的（如刚附加的线程只能看到系统 ClassLoader），之后可以重试
+func (h *memberHandle) resolve(env Env, field, static bool, t reflect.Type) error {
+	if h.resolved.Load() {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.resolved.Load() {
+		return nil
+	}
+
+	h.static = static
+	if err := h.check(field, t); err != nil {
+		return err
+	}
+	class, id, err := env.member(memberKey{class: h.className, name: h.name, sig: h.sig, field: field, static: static})
+	if err != nil {
+		return err
+	}
+	h.class, h.id = class, id
+	h.resolved.Store(true)
+	return nil
+}
+
+// 检查 Go 类型 t 与描述符是否匹配
+func (h *memberHandle) check(field bool, t reflect.Type) error {
+	desc := h.sig
+	if !field {
+		_, ret, err := classfile.ParseMethodDescriptor(h.sig)
+		if err != nil {
+			return err
+		}
+		h.ret, desc = ret, ret
+		if ret == "V" {
+			if t != reflect.TypeFor[struct{}]() {
+				return fmt.Errorf("jni: %s.%s%s 没有返回值，应使用 struct{}", h.className, h.name, h.sig)
+			}
+			return nil
+		}
+	}
+	if err := checkDescriptor(t, desc); err != nil {
+		return fmt.Errorf("jni: %s.%s: %w", h.className, h.name, err)
+	}
+	return nil
+}
+
+// Get 和 Set 在出错时 panic
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// 读取字段值，静态字段的 obj 为 0
+func (h *memberHandle) fieldValue(env Env, obj Jobject) Jvalue {
+	if h.static {
+		switch h.sig[0] {
+		case 'Z':
+			return ValueOf(env.GetStaticBooleanField(h.class, h.id))
+		case 'B':
+			return ValueOf(env.GetStaticByteField(h.class, h.id))
+		case 'C':
+			return ValueOf(env.GetStaticCharField(h.class, h.id))
+		case 'S':
+			return ValueOf(env.GetStaticShortField(h.class, h.id))
+		case 'I':
+			return ValueOf(env.GetStaticIntField(h.class, h.id))
+		case 'J':
+			return ValueOf(env.GetStaticLongField(h.class, h.id))
+		case 'F':
+			return ValueOf(env.GetStaticFloatField(h.class, h.id))
+		case 'D':
+			return ValueOf(env.GetStaticDoubleField(h.class, h.id))
+		default:
+			return ValueOf(env.GetStaticObjectField(h.class, h.id))
+		}
+	}
+
+	switch h.sig[0] {
+	case 'Z':
+		return ValueOf(env.GetBooleanField(obj, h.id))
+	case 'B':
+		return ValueOf(env.GetByteField(obj, h.id))
+	case 'C':
+		return ValueOf(env.GetCharField(obj, h.id))
+	case 'S':
+		return ValueOf(env.GetShortField(obj, h.id))
+	case 'I':
+		return ValueOf(env.GetIntField(obj, h.id))
+	case 'J':
+		return ValueOf(env.GetLongField(obj, h.id))
+	case 'F':
+		return ValueOf(env.GetFloatField(obj, h.id))
+	case 'D':
+		return ValueOf(env.GetDoubleField(obj, h.id))
+	default:
+		return ValueOf(env.GetObjectField(obj, h.id))
+	}
+}
+
+// 设置字段值，静态字段的 obj 为 0
+func (h *memberHandle) setFieldValue(env Env, obj Jobject, v reflect.Value) error {
+	var val Jvalue
+	if p := h.sig[0]; !isReferenceDescriptor(h.sig) {
+		val = primitiveValue(v, p)
+	} else {
+		ref, err := env.toJava(v, h.sig)
+		if err != nil {
+			return fmt.Errorf("jni: %s.%s: %w", h.className, h.name, err)
+		}
+		if ref != 0 {
+			defer env.DeleteLocalRef(ref)
+		}
+		val = ValueOf(ref)
+	}
+
+	if h.static {
+		switch h.sig[0] {
+		case 'Z':
+			env.SetStaticBooleanField(h.class, h.id, ValueAs[bool](val))
+		case 'B':
+			env.SetStaticByteField(h.class, h.id, ValueAs[byte](val))
+		case 'C':
+			env.SetStaticCharField(h.class, h.id, ValueAs[uint16](val))
+		case 'S':
+			env.SetStaticShortField(h.class, h.id, ValueAs[int16](val))
+		case 'I':
+			env.SetStaticIntField(h.class, h.id, ValueAs[int](val))
+		case 'J':
+			env.SetStaticLongField(h.class, h.id, ValueAs[int64](val))
+		case 'F':
+			env.SetStaticFloatField(h.class, h.id, ValueAs[float32](val))
+		case 'D':
+			env.SetStaticDoubleField(h.class, h.id, ValueAs[float64](val))
+		default:
+			env.SetStaticObjectField(h.class, h.id, ValueAs[uintptr](val))
+		}
+		return nil
+	}
+
+	switch h.sig[0] {
+	case 'Z':
+		env.SetBooleanField(obj, h.id, ValueAs[bool](val))
+	case 'B':
+		env.SetByteField(obj, h.id, ValueAs[byte](val))
+	case 'C':
+		env.SetCharField(obj, h.id, ValueAs[uint16](val))
+	case 'S':
+		env.SetShortField(obj, h.id, ValueAs[int16](val))
+	case 'I':
+		env.SetIntField(obj, h.id, ValueAs[int](val))
+	case 'J':
+		env.SetLongField(obj, h.id, ValueAs[int64](val))
+	case 'F':
+		env.SetFloatField(obj, h.id, ValueAs[float32](val))
+	case 'D':
+		env.SetDoubleField(obj, h.id, ValueAs[float64](val))
+	default:
+		env.SetObjectField(obj, h.id, ValueAs[uintptr](val))
+	}
+	return nil
+}
+
+// 调用方法并把返回值写入 v，静态方法的 obj 为 0
+func (h *memberHandle) call(env Env, obj Jobject, args []any, v reflect.Value) error {
+	vals, err := Values(h.sig, args...)
+	if err != nil {
+		return err
+	}
+
+	var ret Jvalue
+	if h.static {
+		switch h.ret[0] {
+		case 'V':
+			env.CallStaticVoidMethodA(h.class, h.id, vals...)
+		case 'Z':
+			ret = ValueOf(env.CallStaticBooleanMethodA(h.class, h.id, vals...))
+		case 'B':
+			ret = ValueOf(env.CallStaticByteMethodA(h.class, h.id, vals...))
+		case 'C':
+			ret = ValueOf(env.CallStaticCharMethodA(h.class, h.id, vals...))
+		case 'S':
+			ret = ValueOf(env.CallStaticShortMethodA(h.class, h.id, vals...))
+		case 'I':
+			ret = ValueOf(env.CallStaticIntMethodA(h.class, h.id, vals...))
+		case 'J':
+			ret = ValueOf(env.CallStaticLongMethodA(h.class, h.id, vals...))
+		case 'F':
+			ret = ValueOf(env.CallStaticFloatMethodA(h.class, h.id, vals...))
+		case 'D':
+			ret = ValueOf(env.CallStaticDoubleMethodA(h.class, h.id, vals...))
+		default:
+			ret = ValueOf(env.CallStaticObjectMethodA(h.class, h.id, vals...))
+		}
+	} else {
+		switch h.ret[0] {
+		case 'V':
+			env.CallVoidMethodA(obj, h.id, vals...)
+		case 'Z':
+			ret = ValueOf(env.CallBooleanMethodA(obj, h.id, vals...))
+		case 'B':
+			ret = ValueOf(env.CallByteMethodA(obj, h.id, vals...))
+		case 'C':
+			ret = ValueOf(env.CallCharMethodA(obj, h.id, vals...))
+		case 'S':
+			ret = ValueOf(env.CallShortMethodA(obj, h.id, vals...))
+		case 'I':
+			ret = ValueOf(env.CallIntMethodA(obj, h.id, vals...))
+		case 'J':
+			ret = ValueOf(env.CallLongMethodA(obj, h.id, vals...))
+		case 'F':
+			ret = ValueOf(env.CallFloatMethodA(obj, h.id, vals...))
+		case 'D':
+			ret = ValueOf(env.CallDoubleMethodA(obj, h.id, vals...))
+		default:
+			ret = ValueOf(env.CallObjectMethodA(obj, h.id, vals...))
+		}
+	}
+
+	if err = env.CheckException(); err != nil {
+		return err
+	}
+	if h.ret == "V" {
+		return nil
+	}
+	return env.storeValue(ret, h.ret, v)
+}
+
+// 把描述符为 sig 的 Jvalue 写入 v，引用类型的局部引用在转换后删除
+func (env Env) storeValue(val Jvalue, sig string, v reflect.Value) error {
+	switch sig[0] {
+	case 'Z':
+		v.SetBool(ValueAs[bool](val))
+	case 'B':
+		setInt(v, int64(ValueAs[int8](val)))
+	case 'C':
+		setInt(v, int64(ValueAs[uint16](val)))
+	case 'S':
+		setInt(v, int64(ValueAs[int16](val)))
+	case 'I':
+		setInt(v, int64(ValueAs[int32](val)))
+	case 'J':
+		setInt(v, ValueAs[int64](val))
+	case 'F':
+		v.SetFloat(float64(ValueAs[float32](val)))
+	case 'D':
+		v.SetFloat(ValueAs[float64](val))
+	default:
+		ref := ValueAs[uintptr](val)
+		if ref != 0 {
+			defer env.DeleteLocalRef(ref)
+		}
+		return env.fromJava(ref, v, sig, &unmarshalOptions{})
+	}
+	return nil
+}
+
+// 把字段值转换为 T
+func getValue[T any](env Env, h *memberHandle, val Jvalue) (T, error) {
+	var ret T
+	if err := env.storeValue(val, h.sig, reflect.ValueOf(&ret).Elem()); err != nil {
+		return ret, fmt.Errorf("jni: %s.%s: %w", h.className, h.name, err)
+	}
+	return ret, nil
+}
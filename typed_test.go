@@ -0,0 +1,35 @@
+package jni
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMemberHandleInit(t *testing.T) {
+	var h memberHandle
+	if err := h.init("java/lang/String", "length", "()I"); err != nil {
+		t.Fatal(err)
+	}
+	// 未解析的成员可以重新绑定
+	if err := h.init("java/lang/String", "isEmpty", "()Z"); err != nil {
+		t.Fatal(err)
+	}
+
+	h.resolved.Store(true)
+	if err := h.init("java/lang/String", "isEmpty", "()Z"); err != nil {
+		t.Errorf("相同的成员: %v", err)
+	}
+	for _, m := range [][3]string{
+		{"java/lang/CharSequence", "isEmpty", "()Z"},
+		{"java/lang/String", "length", "()Z"},
+		{"java/lang/String", "isEmpty", "()I"},
+	} {
+		err := h.init(m[0], m[1], m[2])
+		if err == nil || !strings.Contains(err.Error(), "已经解析") {
+			t.Errorf("init(%q): 错误为 %v，期望已经解析的错误", m, err)
+		}
+	}
+	if h.className != "java/lang/String" || h.name != "isEmpty" || h.sig != "()Z" {
+		t.Errorf("已经解析的成员被修改为 %s.%s%s", h.className, h.name, h.sig)
+	}
+}
@@ -0,0 +1,239 @@
+package jni
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UnmarshalOption 用于调整 Unmarshal 的行为
+type UnmarshalOption func(*unmarshalOptions)
+
+type unmarshalOptions struct {
+	getters bool
+}
+
+// UseGetters 让 Unmarshal 通过 getFoo()/isFoo() 方法读取属性，而不是直接访问字段
+func UseGetters() UnmarshalOption {
+	return func(o *unmarshalOptions) {
+		o.getters = true
+	}
+}
+
+// Unmarshal 是 Marshal 的逆操作：按照 `jni:"fieldName"` 标签把 Java 对象 obj
+// 的字段读入 v 指向的结构体。
+//
+// 解析字段时会检查 Go 类型与 Java 字段描述符是否匹配。嵌套对象、数组、字符串都会被复制为 Go 值；
+// 包装类（如 java.lang.Integer）对应 *基本类型，Java null 对应 nil。
+// Jobject 类型的字段得到新的局部引用，由调用者负责释放。
+func Unmarshal(env Env, obj Jobject, v any, opts ...UnmarshalOption) error {
+	var o unmarshalOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("jni: Unmarshal 需要非 nil 的结构体指针，实际为 %T", v)
+	}
+	if obj == 0 {
+		return fmt.Errorf("jni: Unmarshal 的对象为 null")
+	}
+
+	className, err := env.classOf(obj)
+	if err != nil {
+		return err
+	}
+	return env.unmarshalStruct(obj, rv.Elem(), className, &o)
+}
+
+func (env Env) unmarshalStruct(obj Jobject, v reflect.Value, className string, o *unmarshalOptions) error {
+	info, err := env.structInfoOf(v.Type(), className, o.getters)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range info.fields {
+		if err = env.getField(obj, f, v.Field(f.index), o); err != nil {
+			return fmt.Errorf("jni: 读取 %s.%s: %w", className, f.name, err)
+		}
+	}
+	return nil
+}
+
+func (env Env) getField(obj Jobject, f structField, v reflect.Value, o *unmarshalOptions) error {
+	if f.getter != 0 {
+		return env.callGetter(obj, f, v, o)
+	}
+
+	switch f.sig[0] {
+	case 'Z':
+		v.SetBool(env.GetBooleanField(obj, f.id))
+	case 'B':
+		setInt(v, int64(int8(env.GetByteField(obj, f.id))))
+	case 'C':
+		setInt(v, int64(env.GetCharField(obj, f.id)))
+	case 'S':
+		setInt(v, int64(env.GetShortField(obj, f.id)))
+	case 'I':
+		setInt(v, int64(env.GetIntField(obj, f.id)))
+	case 'J':
+		setInt(v, env.GetLongField(obj, f.id))
+	case 'F':
+		v.SetFloat(float64(env.GetFloatField(obj, f.id)))
+	case 'D':
+		v.SetFloat(env.GetDoubleField(obj, f.id))
+	default:
+		ref := env.GetObjectField(obj, f.id)
+		if ref != 0 {
+			defer env.DeleteLocalRef(ref)
+		}
+		return env.fromJava(ref, v, f.sig, o)
+	}
+	return nil
+}
+
+func (env Env) callGetter(obj Jobject, f structField, v reflect.Value, o *unmarshalOptions) error {
+	var ref Jobject
+	switch f.sig[0] {
+	case 'Z':
+		v.SetBool(env.CallBooleanMethodA(obj, f.getter))
+	case 'B':
+		setInt(v, int64(int8(env.CallByteMethodA(obj, f.getter))))
+	case 'C':
+		setInt(v, int64(env.CallCharMethodA(obj, f.getter)))
+	case 'S':
+		setInt(v, int64(env.CallShortMethodA(obj, f.getter)))
+	case 'I':
+		setInt(v, int64(env.CallIntMethodA(obj, f.getter)))
+	case 'J':
+		setInt(v, env.CallLongMethodA(obj, f.getter))
+	case 'F':
+		v.SetFloat(float64(env.CallFloatMethodA(obj, f.getter)))
+	case 'D':
+		v.SetFloat(env.CallDoubleMethodA(obj, f.getter))
+	default:
+		ref = env.CallObjectMethodA(obj, f.getter)
+	}
+
+	// getter 可能抛出异常
+	if err := env.CheckException(); err != nil {
+		return err
+	}
+	if isReferenceDescriptor(f.sig) {
+		if ref != 0 {
+			defer env.DeleteLocalRef(ref)
+		}
+		return env.fromJava(ref, v, f.sig, o)
+	}
+	return nil
+}
+
+// 把描述符为 sig 的 Java 引用转换为 Go 值并写入 v
+func (env Env) fromJava(ref Jobject, v reflect.Value, sig string, o *unmarshalOptions) error {
+	if ref == 0 {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if p, ok := primitiveKinds[elem.Elem().Kind()]; ok {
+			if err := env.unbox(ref, p, elem.Elem()); err != nil {
+				return err
+			}
+		} else if err := env.fromJava(ref, elem.Elem(), sig, o); err != nil {
+			return err
+		}
+		v.Set(elem)
+
+	case reflect.String:
+		v.SetString(env.goString(ref))
+
+	case reflect.Slice:
+		return env.readArray(ref, v, sig[1:], o)
+
+	case reflect.Struct:
+		// 使用对象的实际类型，子类对象和应用 ClassLoader 加载的类也能正确解析
+		className, err := env.classOf(ref)
+		if err != nil {
+			return err
+		}
+		return env.unmarshalStruct(ref, v, className, o)
+
+	case reflect.Uintptr:
+		v.SetUint(uint64(env.NewLocalRef(ref)))
+
+	default:
+		return fmt.Errorf("不支持的类型 %s", v.Type())
+	}
+	return nil
+}
+
+// 把元素描述符为 elem 的 Java 数组复制到切片 v 中
+func (env Env) readArray(arr Jarray, v reflect.Value, elem string, o *unmarshalOptions) error {
+	n := env.GetArrayLength(arr)
+	v.Set(reflect.MakeSlice(v.Type(), n, n))
+
+	switch elem[0] {
+	case 'Z':
+		buf := make([]bool, n)
+		env.GetBooleanArrayRegion(arr, 0, buf)
+		fillSlice(v, buf, reflect.Value.SetBool)
+	case 'B':
+		buf := make([]byte, n)
+		env.GetByteArrayRegion(arr, 0, buf)
+		fillSlice(v, buf, func(e reflect.Value, b byte) { setInt(e, int64(int8(b))) })
+	case 'C':
+		buf := make([]uint16, n)
+		env.GetCharArrayRegion(arr, 0, buf)
+		fillSlice(v, buf, func(e reflect.Value, c uint16) { setInt(e, int64(c)) })
+	case 'S':
+		buf := make([]int16, n)
+		env.GetShortArrayRegion(arr, 0, buf)
+		fillSlice(v, buf, func(e reflect.Value, s int16) { setInt(e, int64(s)) })
+	case 'I':
+		buf := make([]int32, n)
+		env.GetIntArrayRegion(arr, 0, buf)
+		fillSlice(v, buf, func(e reflect.Value, i int32) { setInt(e, int64(i)) })
+	case 'J':
+		buf := make([]int64, n)
+		env.GetLongArrayRegion(arr, 0, buf)
+		fillSlice(v, buf, setInt)
+	case 'F':
+		buf := make([]float32, n)
+		env.GetFloatArrayRegion(arr, 0, buf)
+		fillSlice(v, buf, func(e reflect.Value, f float32) { e.SetFloat(float64(f)) })
+	case 'D':
+		buf := make([]float64, n)
+		env.GetDoubleArrayRegion(arr, 0, buf)
+		fillSlice(v, buf, reflect.Value.SetFloat)
+	default:
+		for i := 0; i < n; i++ {
+			ref := env.GetObjectArrayElement(arr, i)
+			err := env.fromJava(ref, v.Index(i), elem, o)
+			if ref != 0 {
+				env.DeleteLocalRef(ref)
+			}
+			if err != nil {
+				return fmt.Errorf("第 %d 个元素: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// 写入有符号或无符号整数
+func setInt(v reflect.Value, i int64) {
+	if v.CanInt() {
+		v.SetInt(i)
+	} else {
+		v.SetUint(uint64(i))
+	}
+}
+
+func fillSlice[T any](v reflect.Value, buf []T, set func(reflect.Value, T)) {
+	for i, e := range buf {
+		set(v.Index(i), e)
+	}
+}
@@ -0,0 +1,214 @@
+package jni
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+
+	"github.com/ClarkGuan/jni/classfile"
+)
+
+// Value 是可以放入 jvalue 联合体的 Go 类型：
+//
+//	bool             -> jboolean (z)
+//	int8, byte       -> jbyte    (b)
+//	uint16           -> jchar    (c)
+//	int16            -> jshort   (s)
+//	int32, int       -> jint     (i)
+//	int64            -> jlong    (j)
+//	float32          -> jfloat   (f)
+//	float64          -> jdouble  (d)
+//	uintptr(Jobject) -> jobject  (l)
+//
+// int 和本库其它地方一样表示 jint：无论在 32 位还是 64 位平台上都截断为 32 位，
+// 需要 jlong 时请使用 int64。
+type Value interface {
+	Primitive | uintptr
+}
+
+// ValueOf 把 v 写入 jvalue 中对应的联合体成员，其余字节为 0。
+// 写入位置与 C 的联合体布局一致，不依赖平台字节序。
+func ValueOf[T Value](v T) Jvalue {
+	var val Jvalue
+	p := unsafe.Pointer(&val)
+	switch x := any(v).(type) {
+	case bool:
+		if x {
+			*(*uint8)(p) = JNI_TRUE
+		}
+	case int8:
+		*(*int8)(p) = x
+	case byte:
+		*(*byte)(p) = x
+	case uint16:
+		*(*uint16)(p) = x
+	case int16:
+		*(*int16)(p) = x
+	case int32:
+		*(*int32)(p) = x
+	case int:
+		*(*int32)(p) = int32(x)
+	case int64:
+		*(*int64)(p) = x
+	case float32:
+		*(*float32)(p) = x
+	case float64:
+		*(*float64)(p) = x
+	case uintptr:
+		*(*uintptr)(p) = x
+	}
+	return val
+}
+
+// ValueAs 是 ValueOf 的逆操作，从 jvalue 中读出对应的联合体成员。
+// T 为 int 时按 jint 读取并做符号扩展。
+func ValueAs[T Value](val Jvalue) T {
+	var ret T
+	p := unsafe.Pointer(&val)
+	switch r := any(&ret).(type) {
+	case *bool:
+		*r = *(*uint8)(p) != 0
+	case *int8:
+		*r = *(*int8)(p)
+	case *byte:
+		*r = *(*byte)(p)
+	case *uint16:
+		*r = *(*uint16)(p)
+	case *int16:
+		*r = *(*int16)(p)
+	case *int32:
+		*r = *(*int32)(p)
+	case *int:
+		*r = int(*(*int32)(p))
+	case *int64:
+		*r = *(*int64)(p)
+	case *float32:
+		*r = *(*float32)(p)
+	case *float64:
+		*r = *(*float64)(p)
+	case *uintptr:
+		*r = *(*uintptr)(p)
+	}
+	return ret
+}
+
+func LongValue(i int64) Jvalue {
+	return ValueOf(i)
+}
+
+func CharValue(c uint16) Jvalue {
+	return ValueOf(c)
+}
+
+func ShortValue(s int16) Jvalue {
+	return ValueOf(s)
+}
+
+func ByteValue(b byte) Jvalue {
+	return ValueOf(b)
+}
+
+func ObjectValue(obj Jobject) Jvalue {
+	return ValueOf(obj)
+}
+
+// Values 按照方法描述符（如 "(ILjava/lang/String;[B)V"）把 args 转换为 []Jvalue，
+// 可以直接传给 Call<Type>MethodA 和 NewObjectA。
+//
+// 整数类型只要取值在 Java 类型的范围内即可，例外是 byte（uint8）可以按位转换为 jbyte，
+// 如 byte(200) 对应 Java 的 -56；引用类型接受 Jobject 或 nil（即 Java null）。
+func Values(descriptor string, args ...any) ([]Jvalue, error) {
+	params, _, err := classfile.ParseMethodDescriptor(descriptor)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) != len(args) {
+		return nil, fmt.Errorf("jni: %s 需要 %d 个参数，实际为 %d 个", descriptor, len(params), len(args))
+	}
+
+	vals := make([]Jvalue, len(args))
+	for i, arg := range args {
+		if vals[i], err = valueFor(params[i], arg); err != nil {
+			return nil, fmt.Errorf("jni: %s 第 %d 个参数: %w", descriptor, i+1, err)
+		}
+	}
+	return vals, nil
+}
+
+// 按照字段描述符转换单个参数
+func valueFor(desc string, arg any) (Jvalue, error) {
+	if arg == nil {
+		if isReferenceDescriptor(desc) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("nil 不能转换为 %s", desc)
+	}
+
+	v := reflect.ValueOf(arg)
+	switch desc[0] {
+	case 'Z':
+		if v.Kind() == reflect.Bool {
+			return ValueOf(v.Bool()), nil
+		}
+
+	case 'B':
+		// byte 按位转换为 jbyte，200 对应 Java 的 -56
+		if v.Kind() == reflect.Uint8 {
+			return ValueOf(int8(v.Uint())), nil
+		}
+		if i, ok := integerIn(v, math.MinInt8, math.MaxInt8); ok {
+			return ValueOf(int8(i)), nil
+		}
+
+	case 'C':
+		if i, ok := integerIn(v, 0, math.MaxUint16); ok {
+			return ValueOf(uint16(i)), nil
+		}
+
+	case 'S':
+		if i, ok := integerIn(v, math.MinInt16, math.MaxInt16); ok {
+			return ValueOf(int16(i)), nil
+		}
+
+	case 'I':
+		if i, ok := integerIn(v, math.MinInt32, math.MaxInt32); ok {
+			return ValueOf(int32(i)), nil
+		}
+
+	case 'J':
+		if i, ok := integerIn(v, math.MinInt64, math.MaxInt64); ok {
+			return ValueOf(i), nil
+		}
+
+	case 'F':
+		if v.CanFloat() {
+			return ValueOf(float32(v.Float())), nil
+		}
+
+	case 'D':
+		if v.CanFloat() {
+			return ValueOf(v.Float()), nil
+		}
+
+	case 'L', '[':
+		if v.Kind() == reflect.Uintptr {
+			return ValueOf(uintptr(v.Uint())), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%T 不能转换为 %s", arg, desc)
+}
+
+// 取出整数值并检查是否在 [min, max] 范围内
+func integerIn(v reflect.Value, min, max int64) (int64, bool) {
+	if v.CanInt() {
+		i := v.Int()
+		return i, i >= min && i <= max
+	}
+	if v.CanUint() && v.Kind() != reflect.Uintptr {
+		u := v.Uint()
+		return int64(u), u <= math.MaxInt64 && int64(u) <= max
+	}
+	return 0, false
+}
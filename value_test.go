@@ -0,0 +1,157 @@
+package jni
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// 返回内存中前几个字节为 b、其余为 0 的 jvalue
+func jvalueBytes(b ...byte) Jvalue {
+	var val Jvalue
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(&val)), unsafe.Sizeof(val)), b)
+	return val
+}
+
+// 按本机字节序返回 n 字节整数 x 的内存表示
+func nativeBytes(x uint64, n int) []byte {
+	b := make([]byte, 8)
+	*(*uint64)(unsafe.Pointer(&b[0])) = x
+	if jvalueBytes(1) != 1 {
+		// 大端序：低位字节在后
+		return b[8-n:]
+	}
+	return b[:n]
+}
+
+func TestValueOf(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Jvalue
+		want Jvalue
+	}{
+		{"true", ValueOf(true), jvalueBytes(JNI_TRUE)},
+		{"false", ValueOf(false), 0},
+		{"int8", ValueOf(int8(-1)), jvalueBytes(0xFF)},
+		{"byte", ValueOf(byte(200)), jvalueBytes(200)},
+		{"uint16", ValueOf(uint16(0xFFFF)), jvalueBytes(0xFF, 0xFF)},
+		{"int16", ValueOf(int16(-2)), jvalueBytes(nativeBytes(0xFFFE, 2)...)},
+		{"int32", ValueOf(int32(-1)), jvalueBytes(0xFF, 0xFF, 0xFF, 0xFF)},
+		// int 截断为 jint，高 4 字节为 0
+		{"int", ValueOf(-1), jvalueBytes(0xFF, 0xFF, 0xFF, 0xFF)},
+		{"int 截断", ValueOf(1<<32 + 5), jvalueBytes(nativeBytes(5, 4)...)},
+		{"int64", ValueOf(int64(-1)), math.MaxUint64},
+		{"float32", ValueOf(float32(1.5)), jvalueBytes(nativeBytes(uint64(math.Float32bits(1.5)), 4)...)},
+		{"float64", ValueOf(1.5), Jvalue(math.Float64bits(1.5))},
+		{"Jobject", ValueOf(Jobject(0x1234)), jvalueBytes(nativeBytes(0x1234, int(unsafe.Sizeof(Jobject(0))))...)},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("ValueOf(%s) = %#x, want %#x", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestValueAs(t *testing.T) {
+	if got := ValueAs[bool](ValueOf(true)); !got {
+		t.Errorf("ValueAs[bool] = %v", got)
+	}
+	if got := ValueAs[int8](ValueOf(int8(-128))); got != -128 {
+		t.Errorf("ValueAs[int8] = %d", got)
+	}
+	if got := ValueAs[byte](ValueOf(int8(-1))); got != 255 {
+		t.Errorf("ValueAs[byte] = %d", got)
+	}
+	if got := ValueAs[uint16](ValueOf(uint16(0x4F60))); got != 0x4F60 {
+		t.Errorf("ValueAs[uint16] = %#x", got)
+	}
+	if got := ValueAs[int16](ValueOf(int16(math.MinInt16))); got != math.MinInt16 {
+		t.Errorf("ValueAs[int16] = %d", got)
+	}
+	if got := ValueAs[int32](ValueOf(int32(math.MinInt32))); got != math.MinInt32 {
+		t.Errorf("ValueAs[int32] = %d", got)
+	}
+	// int 按 jint 读取，忽略高 4 字节并做符号扩展
+	if got := ValueAs[int](ValueOf(int32(math.MinInt32)) | jvalueBytes(0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF)); got != math.MinInt32 {
+		t.Errorf("ValueAs[int] = %d", got)
+	}
+	if got := ValueAs[int64](ValueOf(int64(math.MinInt64))); got != math.MinInt64 {
+		t.Errorf("ValueAs[int64] = %d", got)
+	}
+	if got := ValueAs[float32](ValueOf(float32(-0.25))); got != -0.25 {
+		t.Errorf("ValueAs[float32] = %v", got)
+	}
+	if got := ValueAs[float64](ValueOf(math.Pi)); got != math.Pi {
+		t.Errorf("ValueAs[float64] = %v", got)
+	}
+	if got := ValueAs[uintptr](ValueOf(Jobject(0xBEEF))); got != 0xBEEF {
+		t.Errorf("ValueAs[uintptr] = %#x", got)
+	}
+}
+
+func TestValues(t *testing.T) {
+	type myInt int16
+
+	tests := []struct {
+		desc string
+		args []any
+		want []Jvalue
+	}{
+		{"()V", nil, []Jvalue{}},
+		{"(ZBCSIJFD)V",
+			[]any{true, -1, 'A', myInt(-2), uint32(7), 8, float32(1.5), 2.5},
+			[]Jvalue{ValueOf(true), ValueOf(int8(-1)), ValueOf(uint16('A')), ValueOf(int16(-2)),
+				ValueOf(int32(7)), ValueOf(int64(8)), ValueOf(float32(1.5)), ValueOf(2.5)}},
+		// byte 按位转换，其他整数类型必须在 jbyte 的范围内
+		{"(BB)V", []any{byte(200), int8(-128)}, []Jvalue{ValueOf(int8(-56)), ValueOf(int8(-128))}},
+		// float64 可以传给 float，int64 的最值可以传给 long
+		{"(FJ)V", []any{0.5, int64(math.MinInt64)}, []Jvalue{ValueOf(float32(0.5)), ValueOf(int64(math.MinInt64))}},
+		{"(Ljava/lang/String;[I)V", []any{Jobject(0x10), nil}, []Jvalue{ValueOf(Jobject(0x10)), 0}},
+	}
+	for _, tt := range tests {
+		got, err := Values(tt.desc, tt.args...)
+		if err != nil {
+			t.Errorf("Values(%q): %v", tt.desc, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Values(%q) 返回 %d 个值，期望 %d 个", tt.desc, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Values(%q) 第 %d 个值为 %#x，期望 %#x", tt.desc, i+1, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestValuesError(t *testing.T) {
+	tests := []struct {
+		desc string
+		args []any
+		want string
+	}{
+		{"I)V", []any{1}, "无效的方法描述符"},
+		{"(L;)V", []any{nil}, "无效的方法描述符"},
+		{"(II)V", []any{1}, "需要 2 个参数，实际为 1 个"},
+		{"(B)V", []any{128}, "第 1 个参数: int 不能转换为 B"},
+		{"(B)V", []any{uint16(200)}, "uint16 不能转换为 B"},
+		{"(C)V", []any{-1}, "int 不能转换为 C"},
+		{"(S)V", []any{math.MaxInt16 + 1}, "int 不能转换为 S"},
+		{"(I)V", []any{int64(math.MaxInt32 + 1)}, "int64 不能转换为 I"},
+		{"(J)V", []any{uint64(math.MaxUint64)}, "uint64 不能转换为 J"},
+		{"(I)V", []any{1.0}, "float64 不能转换为 I"},
+		{"(Z)V", []any{1}, "int 不能转换为 Z"},
+		{"(D)V", []any{nil}, "nil 不能转换为 D"},
+		{"(Ljava/lang/Object;)V", []any{"s"}, "string 不能转换为 Ljava/lang/Object;"},
+		{"(IJ)V", []any{1, "2"}, "第 2 个参数: string 不能转换为 J"},
+	}
+	for _, tt := range tests {
+		_, err := Values(tt.desc, tt.args...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Values(%q, %v): 错误为 %v，期望包含 %q", tt.desc, tt.args, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package jni
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupported 表示当前 JVM 的 JNI 版本低于函数所要求的版本
+var ErrUnsupported = errors.New("jni: 当前 JVM 不支持该函数")
+
+// 检查 JNI 版本，避免调用函数表中不存在的函数指针
+func (env Env) requireVersion(version int, name string) error {
+	if v := env.GetVersion(); v < version {
+		return fmt.Errorf("%w: %s 需要 JNI 版本 %#x，当前为 %#x", ErrUnsupported, name, version, v)
+	}
+	return nil
+}